@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// credential is a token cached by login for one server.
+type credential struct {
+	AccessToken string `json:"access_token"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+// credentialsPath is the file login caches tokens in, keyed by server
+// address. It holds bearer tokens and is only readable by its owner.
+func credentialsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "identity", "credentials"), nil
+}
+
+func readCredentials() (map[string]credential, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]credential{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	creds := map[string]credential{}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// cachedToken returns the token cached for addr, or "" if there is none or it
+// has expired.
+func cachedToken(addr string) (string, error) {
+	creds, err := readCredentials()
+	if err != nil {
+		return "", err
+	}
+	c, ok := creds[addr]
+	if !ok || (c.ExpiresAt != 0 && time.Now().Unix() >= c.ExpiresAt) {
+		return "", nil
+	}
+	return c.AccessToken, nil
+}
+
+// cacheToken stores the token for addr, replacing the file so that it is never
+// seen half written.
+func cacheToken(addr string, c credential) error {
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	creds[addr] = c
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".credentials")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,45 +2,236 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"os"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+	netcontext "golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	pb "github.com/tthanh/identity-demo/proto"
 )
 
+// Exit codes of the client. A failed call exits with exitCallFailed plus its
+// gRPC code, e.g. 15 for NotFound and 26 for Unauthenticated.
 const (
-	address = "localhost:50051"
+	exitError      = 1
+	exitUsage      = 2
+	exitCallFailed = 10
 )
 
+var (
+	address    string
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+	token      string
+	timeout    time.Duration
+	output     string
+)
+
+// started is set once the command line has been parsed, so that errors
+// before it are reported as usage errors.
+var started bool
+
+// usageError is an error in the command line.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// callError is an error returned by the identity server.
+type callError struct {
+	err error
+}
+
+func (e callError) Error() string {
+	return fmt.Sprintf("%s: %s", grpc.Code(e.err), grpc.ErrorDesc(e.err))
+}
+
 func main() {
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	cmd := &cobra.Command{
+		Use:   "client",
+		Short: "Calls the Identity gRPC API",
+		Long: `Calls the Identity gRPC API.
+
+A failed call exits with 10 plus its gRPC code, e.g. 15 for NotFound, 17 for
+PermissionDenied and 26 for Unauthenticated. Invalid command lines exit with 2
+and other failures with 1.`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			started = true
+			if _, ok := printers[output]; !ok {
+				return usageError(fmt.Sprintf("unknown output format %q, want table, json or yaml", output))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.StringVar(&address, "address", "localhost:50051", "address of the identity server")
+	f.StringVar(&caFile, "ca", "", "PEM bundle of CAs trusted for the server certificate; enables TLS")
+	f.StringVar(&certFile, "cert", "", "PEM client certificate for mutual TLS")
+	f.StringVar(&keyFile, "key", "", "PEM private key of --cert")
+	f.StringVar(&serverName, "server-name", "", "name expected in the server certificate, defaults to the host of --address")
+	f.StringVar(&token, "token", "", "bearer token sent with every call; defaults to the one login cached for --address")
+	f.DurationVar(&timeout, "timeout", 10*time.Second, "how long the command may take; 0 waits forever")
+	f.StringVarP(&output, "output", "o", "table", "output format, table, json or yaml")
+
+	cmd.AddCommand(usersCommand(), loginCommand(), tokenCommand(), allowedCommand())
+
+	ran, err := cmd.ExecuteC()
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	switch e := err.(type) {
+	case callError:
+		os.Exit(exitCallFailed + int(grpc.Code(e.err)))
+	case usageError:
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", ran.CommandPath())
+		os.Exit(exitUsage)
+	}
+	if !started {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", ran.CommandPath())
+		os.Exit(exitUsage)
+	}
+	os.Exit(exitError)
+}
+
+// groupCommand returns a command that only holds subcommands.
+func groupCommand(use, short string, subcommands ...*cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return usageError(fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath()))
+			}
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(subcommands...)
+	return cmd
+}
+
+// checkArgs fails with a usage error unless there are between min and max
+// arguments; max < 0 allows any number above min.
+func checkArgs(args []string, min, max int) error {
+	switch {
+	case min == max && len(args) != min:
+		return usageError(fmt.Sprintf("expected %d arguments, got %d", min, len(args)))
+	case len(args) < min:
+		return usageError(fmt.Sprintf("expected at least %d arguments, got %d", min, len(args)))
+	case max >= 0 && len(args) > max:
+		return usageError(fmt.Sprintf("expected at most %d arguments, got %d", max, len(args)))
+	}
+	return nil
+}
+
+// call invokes f and prints the message it returns.
+func call(f func(ctx context.Context, c pb.IdentityClient) (proto.Message, error)) error {
+	msg, err := invoke(f)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer conn.Close()
+	return printers[output](os.Stdout, msg)
+}
 
-	iClient := pb.NewIdentityClient(conn)
+// invoke connects to the server and runs f with a context that ends after
+// --timeout. Calls carry --token, or else the token login cached for the
+// server.
+func invoke(f func(ctx context.Context, c pb.IdentityClient) (proto.Message, error)) (proto.Message, error) {
+	opt, err := transportOption()
+	if err != nil {
+		return nil, err
+	}
+
+	bearer := token
+	if bearer == "" {
+		if bearer, err = cachedToken(address); err != nil {
+			return nil, fmt.Errorf("reading cached credentials: %v", err)
+		}
+	}
+
+	opts := []grpc.DialOption{opt}
+	if bearer != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(bearer)))
+	}
 
-	args := os.Args[1:]
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
 
-	if args[0] == "register" {
-		username := args[1]
-		password := args[2]
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	msg, err := f(ctx, pb.NewIdentityClient(conn))
+	if err != nil {
+		return nil, callError{err}
+	}
+	return msg, nil
+}
 
-		req := &pb.RegisterRequest{
-			Username: username,
-			Password: password,
+// transportOption returns the dial option for the configured transport:
+// plaintext unless a CA is given, TLS otherwise, and mutual TLS if a client
+// certificate is given as well.
+func transportOption() (grpc.DialOption, error) {
+	if caFile == "" {
+		if certFile != "" {
+			return nil, errors.New("--cert requires --ca")
 		}
+		return grpc.WithInsecure(), nil
+	}
 
-		res, err := iClient.Register(context.Background(), req)
+	data, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s contains no PEM certificates", caFile)
+	}
+
+	config := &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
-
-		fmt.Printf("%v\n", res.Id)
-		fmt.Printf("%v\n", res.Username)
+		config.Certificates = []tls.Certificate{cert}
 	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+// bearerToken sends a token in the authorization header of every call. Unlike
+// the credentials in grpc/credentials/oauth it also works without TLS, for
+// local development.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(netcontext.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"gopkg.in/yaml.v2"
+)
+
+// printers write a response in the format chosen with --output.
+var printers = map[string]func(io.Writer, proto.Message) error{
+	"table": printTable,
+	"json":  printJSON,
+	"yaml":  printYAML,
+}
+
+// timestampFields are fields, by proto name, that hold seconds since the
+// epoch. Tables show them as times.
+var timestampFields = map[string]bool{
+	"expires_at": true,
+	"issued_at":  true,
+	"exp":        true,
+	"iat":        true,
+	"nbf":        true,
+}
+
+var jsonMarshaler = &jsonpb.Marshaler{OrigName: true, EmitDefaults: true, Indent: "  "}
+
+func printJSON(w io.Writer, msg proto.Message) error {
+	if err := jsonMarshaler.Marshal(w, msg); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// printYAML writes msg as YAML with the fields and values of its JSON
+// mapping, in field order.
+func printYAML(w io.Writer, msg proto.Message) error {
+	data, err := jsonMarshaler.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	var fields yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(data), &fields); err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// printTable writes a list of messages as one row each, and any other message
+// as one row per field that is set.
+func printTable(w io.Writer, msg proto.Message) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	v := reflect.ValueOf(msg).Elem()
+
+	if rows, ok := listField(v); ok {
+		var header []string
+		for _, f := range protoFields(rows.Type().Elem().Elem()) {
+			header = append(header, strings.ToUpper(f.name))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for i := 0; i < rows.Len(); i++ {
+			row := rows.Index(i).Elem()
+			var cells []string
+			for _, f := range protoFields(row.Type()) {
+				cells = append(cells, formatValue(f.name, row.Field(f.index)))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+
+	for _, f := range protoFields(v.Type()) {
+		field := v.Field(f.index)
+		if field.Kind() != reflect.Bool && isZero(field) {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", f.name, formatValue(f.name, field))
+	}
+	return tw.Flush()
+}
+
+// protoField is a field of a generated message.
+type protoField struct {
+	name  string
+	index int
+}
+
+// protoFields returns the fields of the message struct t in declaration
+// order, with their proto names.
+func protoFields(t reflect.Type) []protoField {
+	var fields []protoField
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("protobuf")
+		for _, part := range strings.Split(tag, ",") {
+			if strings.HasPrefix(part, "name=") {
+				fields = append(fields, protoField{strings.TrimPrefix(part, "name="), i})
+			}
+		}
+	}
+	return fields
+}
+
+// listField returns the repeated message field of v if v has one, like the
+// users of a ListUsersResponse.
+func listField(v reflect.Value) (reflect.Value, bool) {
+	for _, f := range protoFields(v.Type()) {
+		field := v.Field(f.index)
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Ptr {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr:
+		return v.IsNil()
+	}
+	return v.Interface() == reflect.Zero(v.Type()).Interface()
+}
+
+func formatValue(name string, v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int64:
+		if timestampFields[name] && v.Int() != 0 {
+			return time.Unix(v.Int(), 0).Format(time.RFC3339)
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		var pairs []string
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, fmt.Sprintf("%v=%v", key.Interface(), v.MapIndex(key).Interface()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// passwordSource reads the passwords a command needs from its arguments,
+// standard input or a file, and otherwise prompts for them on the terminal
+// without echo.
+type passwordSource struct {
+	stdin bool
+	file  string
+}
+
+func (s *passwordSource) addFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.BoolVar(&s.stdin, "password-stdin", false, "read passwords from standard input, one per line")
+	f.StringVar(&s.file, "password-file", "", "read passwords from this file, one per line")
+}
+
+// passwords returns one password per prompt, taken from args if it holds one
+// per prompt. When prompting and confirm is set, the last password, the one
+// being set, is asked for twice.
+func (s *passwordSource) passwords(args []string, confirm bool, prompts ...string) ([]string, error) {
+	given := 0
+	for _, set := range []bool{len(args) > 0, s.stdin, s.file != ""} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, usageError("pass passwords as arguments, with --password-stdin or with --password-file, not several of them")
+	}
+
+	switch {
+	case len(args) > 0:
+		if len(args) != len(prompts) {
+			return nil, usageError(fmt.Sprintf("expected %d passwords, got %d", len(prompts), len(args)))
+		}
+		return args, nil
+	case s.stdin:
+		return readPasswords(os.Stdin, "standard input", len(prompts))
+	case s.file != "":
+		f, err := os.Open(s.file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readPasswords(f, s.file, len(prompts))
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, usageError("standard input is not a terminal, pass the password with --password-stdin or --password-file")
+	}
+	var passwords []string
+	for i, prompt := range prompts {
+		password, err := promptPassword(fd, prompt+": ")
+		if err != nil {
+			return nil, err
+		}
+		if confirm && i == len(prompts)-1 {
+			again, err := promptPassword(fd, "Retype "+strings.ToLower(prompt[:1])+prompt[1:]+": ")
+			if err != nil {
+				return nil, err
+			}
+			if again != password {
+				return nil, errors.New("passwords do not match")
+			}
+		}
+		passwords = append(passwords, password)
+	}
+	return passwords, nil
+}
+
+// readPasswords reads n passwords from the first n lines of r.
+func readPasswords(r io.Reader, name string, n int) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	var passwords []string
+	for len(passwords) < n && scanner.Scan() {
+		passwords = append(passwords, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(passwords) < n {
+		return nil, fmt.Errorf("%s holds %d passwords, expected %d", name, len(passwords), n)
+	}
+	return passwords, nil
+}
+
+// promptPassword asks for a password on the terminal fd without echoing it.
+func promptPassword(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
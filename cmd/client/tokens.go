@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+func loginCommand() *cobra.Command {
+	req := &pb.LoginRequest{}
+	var source passwordSource
+	cmd := &cobra.Command{
+		Use:   "login <username> [password]",
+		Short: "Gets an access token for a user and caches it for later commands",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, 2); err != nil {
+				return err
+			}
+			passwords, err := source.passwords(args[1:], false, "Password")
+			if err != nil {
+				return err
+			}
+			req.Username, req.Password = args[0], passwords[0]
+
+			res, err := invoke(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.Login(ctx, req)
+			})
+			if err != nil {
+				return err
+			}
+			login := res.(*pb.LoginResponse)
+			err = cacheToken(address, credential{AccessToken: login.AccessToken, ExpiresAt: login.ExpiresAt})
+			if err != nil {
+				return fmt.Errorf("caching the token: %v", err)
+			}
+			return printers[output](os.Stdout, res)
+		},
+	}
+	cmd.Flags().StringSliceVar(&req.Scopes, "scope", nil, "scope requested for the token; repeatable")
+	source.addFlags(cmd)
+	return cmd
+}
+
+func tokenCommand() *cobra.Command {
+	return groupCommand("token", "Inspects access tokens",
+		introspectCommand(),
+		validateCommand(),
+		tokenAllowedCommand(),
+	)
+}
+
+func introspectCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "introspect <token>",
+		Short: "Introspects a token as described in RFC 7662",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, 1); err != nil {
+				return err
+			}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.IntrospectToken(ctx, &pb.IntrospectTokenRequest{Token: args[0]})
+			})
+		},
+	}
+}
+
+func validateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <token> [scope...]",
+		Short: "Checks that a token is valid and was granted the scopes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, -1); err != nil {
+				return err
+			}
+			req := &pb.ValidateTokenRequest{Token: args[0], Scopes: args[1:]}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.ValidateToken(ctx, req)
+			})
+		},
+	}
+}
+
+func tokenAllowedCommand() *cobra.Command {
+	var pairs []string
+	cmd := &cobra.Command{
+		Use:   "allowed <token> <action> <resource> [scope...]",
+		Short: "Checks whether the owner of a token may perform an action",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 3, -1); err != nil {
+				return err
+			}
+			access, err := accessRequest("", args[1], args[2], pairs)
+			if err != nil {
+				return err
+			}
+			req := &pb.TokenAllowedRequest{Token: args[0], Request: access, Scopes: args[3:]}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.TokenAllowed(ctx, req)
+			})
+		},
+	}
+	cmd.Flags().StringSliceVar(&pairs, "context", nil, "key=value passed to the policies' conditions; repeatable")
+	return cmd
+}
+
+func allowedCommand() *cobra.Command {
+	var pairs []string
+	cmd := &cobra.Command{
+		Use:   "allowed <subject> <action> <resource>",
+		Short: "Checks whether a subject may perform an action",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 3, 3); err != nil {
+				return err
+			}
+			req, err := accessRequest(args[0], args[1], args[2], pairs)
+			if err != nil {
+				return err
+			}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.IsAllowed(ctx, req)
+			})
+		},
+	}
+	cmd.Flags().StringSliceVar(&pairs, "context", nil, "key=value passed to the policies' conditions; repeatable")
+	return cmd
+}
+
+// accessRequest builds an access request with a context given as key=value
+// pairs.
+func accessRequest(subject, action, resource string, pairs []string) (*pb.AccessRequest, error) {
+	req := &pb.AccessRequest{Subject: subject, Action: action, Resource: resource}
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, usageError(fmt.Sprintf("context %q is not key=value", pair))
+		}
+		if req.Context == nil {
+			req.Context = map[string]string{}
+		}
+		req.Context[pair[:i]] = pair[i+1:]
+	}
+	return req, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+func usersCommand() *cobra.Command {
+	return groupCommand("users", "Manages users",
+		registerCommand(),
+		getCommand(),
+		listCommand(),
+		deleteCommand(),
+		passwdCommand(),
+	)
+}
+
+func registerCommand() *cobra.Command {
+	req := &pb.RegisterRequest{}
+	var source passwordSource
+	cmd := &cobra.Command{
+		Use:   "register <username> [password]",
+		Short: "Registers a user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, 2); err != nil {
+				return err
+			}
+			passwords, err := source.passwords(args[1:], true, "Password")
+			if err != nil {
+				return err
+			}
+			req.Username, req.Password = args[0], passwords[0]
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.Register(ctx, req)
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&req.Id, "id", "", "UUID of the user; random when empty")
+	f.StringVar(&req.Scope, "scope", "", "space separated scopes the user may request")
+	f.StringVar(&req.Owner, "owner", "", "owner of the user's client")
+	f.StringSliceVar(&req.RedirectUris, "redirect-uri", nil, "redirect URI of the user's client; repeatable")
+	f.StringSliceVar(&req.GrantTypes, "grant-type", nil, "grant type the user's client may use; repeatable")
+	f.StringSliceVar(&req.ResponseTypes, "response-type", nil, "response type the user's client may use; repeatable")
+	source.addFlags(cmd)
+	return cmd
+}
+
+func getCommand() *cobra.Command {
+	req := &pb.GetUserRequest{}
+	cmd := &cobra.Command{
+		Use:   "get <id> | --username <username>",
+		Short: "Shows a user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if req.Username != "" {
+				if err := checkArgs(args, 0, 0); err != nil {
+					return err
+				}
+			} else {
+				if err := checkArgs(args, 1, 1); err != nil {
+					return err
+				}
+				req.Id = args[0]
+			}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.GetUser(ctx, req)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&req.Username, "username", "", "look the user up by username instead of id")
+	return cmd
+}
+
+func listCommand() *cobra.Command {
+	req := &pb.ListUsersRequest{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists users, fetching every page",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 0, 0); err != nil {
+				return err
+			}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				all := &pb.ListUsersResponse{}
+				for {
+					res, err := c.ListUsers(ctx, req)
+					if err != nil {
+						return nil, err
+					}
+					all.Users = append(all.Users, res.Users...)
+					if res.NextPageToken == "" {
+						return all, nil
+					}
+					req.PageToken = res.NextPageToken
+				}
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&req.UsernamePrefix, "prefix", "", "only list users whose username starts with this")
+	f.Int32Var(&req.PageSize, "page-size", 0, "users fetched per call; the server's default when 0")
+	return cmd
+}
+
+func deleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Deletes a user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, 1); err != nil {
+				return err
+			}
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.DeleteUser(ctx, &pb.DeleteUserRequest{Id: args[0]})
+			})
+		},
+	}
+}
+
+func passwdCommand() *cobra.Command {
+	req := &pb.ChangePasswordRequest{}
+	var source passwordSource
+	cmd := &cobra.Command{
+		Use:   "passwd <id> [old-password new-password]",
+		Short: "Changes a user's password",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgs(args, 1, 3); err != nil {
+				return err
+			}
+			passwords, err := source.passwords(args[1:], true, "Old password", "New password")
+			if err != nil {
+				return err
+			}
+			req.Id, req.OldPassword, req.NewPassword = args[0], passwords[0], passwords[1]
+			return call(func(ctx context.Context, c pb.IdentityClient) (proto.Message, error) {
+				return c.ChangePassword(ctx, req)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&req.RevokeTokens, "revoke-tokens", false, "treat tokens issued before the change as revoked")
+	source.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory-am/ladon"
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// errForbidden is what hydra's HTTP warden returns when a policy denies an
+// access request.
+const errForbidden = "Forbidden"
+
+func (s *server) IsAllowed(ctx context.Context, req *pb.AccessRequest) (*pb.IsAllowedResponse, error) {
+	if req.Subject == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "subject must not be empty")
+	}
+	if err := validateAccessRequest(req); err != nil {
+		return nil, err
+	}
+
+	err := s.backend.Warden(ctx).IsAllowed(ctx, toLadonRequest(req))
+	if err != nil && err.Error() == errForbidden {
+		return nil, grpc.Errorf(codes.PermissionDenied, "%s: subject %q may not %q resource %q",
+			err, req.Subject, req.Action, req.Resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.IsAllowedResponse{Allowed: true}, nil
+}
+
+func (s *server) TokenAllowed(ctx context.Context, req *pb.TokenAllowedRequest) (*pb.TokenAllowedResponse, error) {
+	if req.Token == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "token must not be empty")
+	}
+	if req.Request == nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "request must be set")
+	}
+	if err := validateAccessRequest(req.Request); err != nil {
+		return nil, err
+	}
+
+	c, err := s.backend.Warden(ctx).TokenAllowed(ctx, req.Token, toLadonRequest(req.Request), req.Scopes...)
+	if err != nil && err.Error() == errTokenInvalid {
+		// hydra does not tell an invalid token apart from a denied request.
+		return nil, grpc.Errorf(codes.PermissionDenied, "token is invalid, lacks scopes %v or may not %q resource %q",
+			req.Scopes, req.Request.Action, req.Request.Resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.revoked.Revoked(c.Audience, c.IssuedAt) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "token has been revoked")
+	}
+
+	res := &pb.TokenAllowedResponse{
+		Subject:       c.Subject,
+		GrantedScopes: c.GrantedScopes,
+		Issuer:        c.Issuer,
+		Audience:      c.Audience,
+		Extra:         stringMap(c.Extra),
+	}
+	if !c.IssuedAt.IsZero() {
+		res.IssuedAt = c.IssuedAt.Unix()
+	}
+	if !c.ExpiresAt.IsZero() {
+		res.ExpiresAt = c.ExpiresAt.Unix()
+	}
+
+	return res, nil
+}
+
+func validateAccessRequest(req *pb.AccessRequest) error {
+	if req.Resource == "" || req.Action == "" {
+		return grpc.Errorf(codes.InvalidArgument, "resource and action must not be empty")
+	}
+	return nil
+}
+
+func toLadonRequest(req *pb.AccessRequest) *ladon.Request {
+	c := ladon.Context{}
+	for k, v := range req.Context {
+		c[k] = v
+	}
+
+	return &ladon.Request{
+		Resource: req.Resource,
+		Action:   req.Action,
+		Subject:  req.Subject,
+		Context:  c,
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ory-am/hydra/firewall"
+	"github.com/ory-am/ladon"
+)
+
+// permission is the ladon resource and action a caller's token must be
+// allowed to access to call a method.
+type permission struct {
+	Resource string
+	Action   string
+}
+
+// methodPermissions lists the permission required by every Identity method.
+// Methods missing from the table are refused, so that new methods are never
+// unprotected by accident.
+var methodPermissions = map[string]permission{
+	"/identity.Identity/Register":        {"rn:identity:users", "create"},
+	"/identity.Identity/GetUser":         {"rn:identity:users", "get"},
+	"/identity.Identity/ListUsers":       {"rn:identity:users", "list"},
+	"/identity.Identity/DeleteUser":      {"rn:identity:users", "delete"},
+	"/identity.Identity/ChangePassword":  {"rn:identity:users", "update"},
+	"/identity.Identity/Login":           {"rn:identity:tokens", "create"},
+	"/identity.Identity/IntrospectToken": {"rn:identity:tokens", "introspect"},
+	"/identity.Identity/ValidateToken":   {"rn:identity:tokens", "validate"},
+	"/identity.Identity/IsAllowed":       {"rn:identity:policies", "check"},
+	"/identity.Identity/TokenAllowed":    {"rn:identity:policies", "check"},
+}
+
+// publicServices are services whose methods are always public, since they
+// serve infrastructure rather than users.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+type authContextKey struct{}
+
+// authContext returns the token session of the caller, which is only set for
+// methods that are not public.
+func authContext(ctx context.Context) (*firewall.Context, bool) {
+	c, ok := ctx.Value(authContextKey{}).(*firewall.Context)
+	return c, ok
+}
+
+// authenticator checks the bearer token of every call to a method that is not
+// public against that method's permission.
+type authenticator struct {
+	public  map[string]bool
+	backend backend
+	revoked *revocationList
+}
+
+// newAuthenticator makes the methods named in public, e.g. "Register",
+// callable without a token.
+func newAuthenticator(public []string, b backend, revoked *revocationList) *authenticator {
+	a := &authenticator{public: map[string]bool{}, backend: b, revoked: revoked}
+	for _, name := range public {
+		a.public["/identity.Identity/"+name] = true
+	}
+	return a
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if a.public[method] {
+		return ctx, nil
+	}
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	perm, ok := methodPermissions[method]
+	if !ok {
+		return nil, grpc.Errorf(codes.PermissionDenied, "%s has no permission configured", method)
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, grpc.Errorf(codes.Unauthenticated, "missing bearer token")
+	}
+
+	c, err := a.backend.Warden(ctx).TokenAllowed(ctx, token, &ladon.Request{
+		Resource: perm.Resource,
+		Action:   perm.Action,
+		Context:  ladon.Context{},
+	})
+	if err != nil && err.Error() == errTokenInvalid {
+		return nil, grpc.Errorf(codes.PermissionDenied, "token is invalid or may not %q resource %q", perm.Action, perm.Resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.revoked.Revoked(c.Audience, c.IssuedAt) {
+		return nil, grpc.Errorf(codes.Unauthenticated, "token has been revoked")
+	}
+
+	logSubject(ctx, c.Subject)
+	return context.WithValue(ctx, authContextKey{}, c), nil
+}
+
+// bearerToken returns the token of an "authorization: bearer" header, or ""
+// if there is none.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md["authorization"] {
+		if len(v) > 7 && strings.EqualFold(v[:7], "bearer ") {
+			return strings.TrimSpace(v[7:])
+		}
+	}
+	return ""
+}
+
+// contextStream carries a context derived by an interceptor, such as the
+// authenticated one, into a stream handler.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// testStream is a grpc.ServerStream that only has a context.
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context { return s.ctx }
+
+// newTestAuthenticator returns an authenticator backed by a memoryBackend and
+// a context carrying the token of a client that may call every method.
+func newTestAuthenticator(t *testing.T, public ...string) (*authenticator, context.Context, string) {
+	b, err := newMemoryBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, secret, err := b.Bootstrap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := b.Token(context.Background(), id, secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := metadata.NewContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token.AccessToken))
+	return newAuthenticator(public, b, newRevocationList()), ctx, id
+}
+
+func TestAuthenticatorUnaryContext(t *testing.T) {
+	a, ctx, id := newTestAuthenticator(t, "Login")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		method  string
+		code    codes.Code
+		subject string
+	}{
+		{"protected", ctx, "/identity.Identity/GetUser", codes.OK, id},
+		{"public", ctx, "/identity.Identity/Login", codes.OK, ""},
+		{"missing token", context.Background(), "/identity.Identity/GetUser", codes.Unauthenticated, ""},
+		{"unknown method", ctx, "/identity.Identity/Unknown", codes.PermissionDenied, ""},
+	}
+
+	for _, test := range tests {
+		var subject string
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			if c, ok := authContext(ctx); ok {
+				subject = c.Subject
+			}
+			return nil, nil
+		}
+
+		_, err := a.unary(test.ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+		if got := code(err); got != test.code {
+			t.Errorf("%s: got %v, want %v (%v)", test.name, got, test.code, err)
+		}
+		if subject != test.subject {
+			t.Errorf("%s: handler saw subject %q, want %q", test.name, subject, test.subject)
+		}
+	}
+}
+
+func TestAuthenticatorStreamContext(t *testing.T) {
+	a, ctx, id := newTestAuthenticator(t)
+
+	var subject string
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		if c, ok := authContext(ss.Context()); ok {
+			subject = c.Subject
+		}
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/identity.Identity/ListUsers"}
+	if err := a.stream(nil, testStream{ctx: ctx}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if subject != id {
+		t.Errorf("handler saw subject %q, want %q", subject, id)
+	}
+}
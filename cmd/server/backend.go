@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ory-am/fosite"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/ory-am/hydra/client"
+	"github.com/ory-am/hydra/connection"
+	"github.com/ory-am/hydra/firewall"
+	"github.com/ory-am/hydra/jwk"
+	hoauth2 "github.com/ory-am/hydra/oauth2"
+	"github.com/ory-am/hydra/pkg"
+	"github.com/ory-am/hydra/policy"
+	"github.com/ory-am/hydra/warden"
+	"github.com/ory-am/ladon"
+)
+
+// backend is everything the Identity service needs from hydra.
+//
+// Implementations fail like hydra's HTTP managers do, e.g. the warden returns
+// errTokenInvalid for a token it rejects, so that handlers need not care
+// which implementation they use. Managers are handed out for the call whose
+// context is passed, so that requests to hydra carry its request ID.
+type backend interface {
+	Clients(ctx context.Context) client.Storage
+	Connections(ctx context.Context) connection.Manager
+	Keys(ctx context.Context) jwk.Manager
+	Policies(ctx context.Context) ladon.Manager
+	Warden(ctx context.Context) firewall.Firewall
+
+	// Introspector introspects tokens on behalf of the owner of the caller
+	// token. Like hydra, it reports only tokens whose audience is that owner
+	// as active.
+	Introspector(ctx context.Context, caller string) hoauth2.Introspector
+
+	// Token runs the client credentials flow for the given client and
+	// returns gRPC status errors.
+	Token(ctx context.Context, id, secret string, scopes []string) (*oauth2.Token, error)
+
+	// Ping checks that hydra answers requests made with the server's own
+	// credentials, giving up when ctx is done.
+	Ping(ctx context.Context) error
+}
+
+// sdkBackend talks to a hydra cluster over HTTP, using the managers of
+// hydra's SDK.
+type sdkBackend struct {
+	endpoint *url.URL
+
+	// clientID is the ID of the server's own client.
+	clientID string
+
+	// authenticated sends the server's own token with every request.
+	authenticated *http.Client
+
+	// http is used for requests made on behalf of users rather than with
+	// the server's own credentials.
+	http *http.Client
+}
+
+var _ backend = (*sdkBackend)(nil)
+
+func (b *sdkBackend) Clients(ctx context.Context) client.Storage {
+	return &client.HTTPManager{Endpoint: pkg.JoinURL(b.endpoint, "/clients"), Client: b.client(ctx)}
+}
+
+func (b *sdkBackend) Connections(ctx context.Context) connection.Manager {
+	return &connection.HTTPManager{Endpoint: pkg.JoinURL(b.endpoint, "/connections"), Client: b.client(ctx)}
+}
+
+func (b *sdkBackend) Keys(ctx context.Context) jwk.Manager {
+	return &jwk.HTTPManager{Endpoint: pkg.JoinURL(b.endpoint, "/keys"), Client: b.client(ctx)}
+}
+
+func (b *sdkBackend) Policies(ctx context.Context) ladon.Manager {
+	return &policy.HTTPManager{Endpoint: pkg.JoinURL(b.endpoint, "/policies"), Client: b.client(ctx)}
+}
+
+func (b *sdkBackend) Warden(ctx context.Context) firewall.Firewall {
+	return &warden.HTTPWarden{Endpoint: b.endpoint, Client: b.client(ctx)}
+}
+
+func (b *sdkBackend) Introspector(ctx context.Context, caller string) hoauth2.Introspector {
+	c := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: caller}),
+			Base:   b.http.Transport,
+		},
+		Timeout: b.http.Timeout,
+	}
+	return callerIntrospector{&hoauth2.HTTPIntrospector{
+		Endpoint: pkg.JoinURL(b.endpoint, hoauth2.IntrospectPath),
+		Client:   withRequestID(ctx, c),
+	}}
+}
+
+// client returns the authenticated client, sending the request ID of the
+// call in ctx along.
+func (b *sdkBackend) client(ctx context.Context) *http.Client {
+	return withRequestID(ctx, b.authenticated)
+}
+
+// callerIntrospector is an oauth2.HTTPIntrospector that authenticates with the
+// caller's token. hydra rejects the request when that token is not valid,
+// which is reported like an inactive token.
+type callerIntrospector struct {
+	*hoauth2.HTTPIntrospector
+}
+
+func (i callerIntrospector) IntrospectToken(ctx context.Context, token string) (*hoauth2.Introspection, error) {
+	res, err := i.HTTPIntrospector.IntrospectToken(ctx, token)
+	if err != nil && callerRejected(err) {
+		return nil, errors.New(errTokenInactive)
+	}
+	return res, err
+}
+
+// callerRejected reports whether hydra refused a request because of the token
+// it was authenticated with. hydra's handlers answer that with status 500 and
+// fosite.ErrRequestUnauthorized.
+func callerRejected(err error) bool {
+	switch hydraStatus(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return strings.HasSuffix(upstreamError(err), fosite.ErrRequestUnauthorized.Error())
+}
+
+// Ping fetches the server's own client, which needs the same credentials and
+// permissions as GetUser. Unlike hydra's managers it stops when ctx is done.
+func (b *sdkBackend) Ping(ctx context.Context) error {
+	req, err := http.NewRequest("GET", pkg.JoinURL(b.endpoint, "clients", b.clientID).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ctxhttp.Do(ctx, withRequestID(ctx, b.authenticated), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hydra answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (b *sdkBackend) Token(ctx context.Context, id, secret string, scopes []string) (*oauth2.Token, error) {
+	conf := clientcredentials.Config{
+		ClientID:     id,
+		ClientSecret: secret,
+		TokenURL:     pkg.JoinURL(b.endpoint, "oauth2/token").String(),
+		Scopes:       scopes,
+	}
+
+	token, err := conf.Token(context.WithValue(ctx, oauth2.HTTPClient, withRequestID(ctx, b.http)))
+	if err != nil {
+		return nil, tokenError(ctx, err)
+	}
+	return token, nil
+}
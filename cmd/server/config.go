@@ -0,0 +1,383 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// envPrefix is prepended to every configuration key to form the name of the
+// environment variable that sets it, e.g. IDENTITY_HYDRA_CLUSTER_URL.
+const envPrefix = "identity"
+
+type config struct {
+	Listen          string
+	UsernameIndex   string
+	Dev             bool
+	EmbeddedHydra   bool
+	Reflection      bool
+	ShutdownTimeout time.Duration
+	MetricsListen   string
+	AdminListen     string
+	GatewayListen   string
+
+	TLS struct {
+		CertFile     string
+		KeyFile      string
+		ClientCAFile string
+	}
+
+	Hydra struct {
+		ClusterURL     string
+		ClientID       string
+		ClientSecret   string
+		CABundle       string
+		Pins           []string
+		ClientCertFile string
+		ClientKeyFile  string
+		Scopes         []string
+		SkipTLSVerify  bool
+
+		ConnectTimeout   time.Duration
+		RequestTimeout   time.Duration
+		RetryTimeout     time.Duration
+		BreakerThreshold int
+		BreakerCooldown  time.Duration
+	}
+
+	Auth struct {
+		Enabled       bool
+		PublicMethods []string
+	}
+
+	Health struct {
+		Interval time.Duration
+	}
+
+	Log struct {
+		Format string
+	}
+
+	Features struct {
+		Register       bool
+		DeleteUser     bool
+		ChangePassword bool
+	}
+}
+
+// addFlags registers a flag for every configuration key on cmd and binds it
+// to v. Flags take precedence over the environment, which takes precedence
+// over the configuration file.
+func addFlags(cmd *cobra.Command, v *viper.Viper) {
+	f := cmd.Flags()
+	f.String("config", "", "configuration file (YAML, TOML or JSON)")
+
+	f.String("listen", ":50051", "address the gRPC server listens on")
+	f.String("username-index", "", "file that persists the username index; kept in memory when empty")
+	f.Bool("dev", false, "keep clients, policies and tokens in memory instead of using hydra")
+	f.Bool("embedded-hydra", false, "serve hydra's API with memory storage in process and use it instead of a hydra cluster")
+	f.String("tls-cert", "", "PEM certificate the gRPC server presents")
+	f.String("tls-key", "", "PEM private key of --tls-cert")
+	f.String("tls-client-ca", "", "PEM bundle of CAs for client certificates; enables mutual TLS")
+	f.String("hydra-url", "https://localhost:4444", "URL of the hydra cluster")
+	f.String("hydra-client-id", "", "client ID the server authenticates to hydra with")
+	f.String("hydra-client-secret", "", "client secret the server authenticates to hydra with")
+	f.String("hydra-ca-bundle", "", "PEM bundle of CAs trusted for hydra's certificate")
+	f.String("hydra-pins", "", "comma separated SHA-256 fingerprints, one of which hydra's certificate chain must contain")
+	f.String("hydra-client-cert", "", "PEM client certificate presented to hydra")
+	f.String("hydra-client-key", "", "PEM private key of --hydra-client-cert")
+	f.String("hydra-scopes", "hydra", "comma separated scopes requested from hydra")
+	f.Bool("hydra-skip-tls-verify", false, "do not verify hydra's certificate; ignored when a CA bundle or pins are set")
+	f.Duration("hydra-connect-timeout", time.Minute, "how long connecting to hydra is retried at startup; 0 retries forever")
+	f.Duration("hydra-request-timeout", 10*time.Second, "how long a single request to hydra may take")
+	f.Duration("hydra-retry-timeout", 2*time.Second, "how long reads and deletes are retried when hydra fails")
+	f.Int("hydra-breaker-threshold", 5, "failed calls in a row after which calls to hydra are suspended")
+	f.Duration("hydra-breaker-cooldown", 30*time.Second, "how long calls to hydra are suspended before trying again")
+	f.Bool("auth", true, "require a bearer token allowed by hydra's policies on every call")
+	f.String("auth-public-methods", "Register,Login", "comma separated methods callable without a token")
+	f.Duration("health-interval", 10*time.Second, "how often hydra is probed for the health service")
+	f.Bool("reflection", true, "register the gRPC server reflection service")
+	f.Duration("shutdown-timeout", 30*time.Second, "how long in-flight calls may take after SIGINT or SIGTERM")
+	f.String("metrics-listen", "", "address serving metrics at /metrics in the Prometheus text format; disabled when empty")
+	f.String("gateway-listen", "", "address serving the Identity API as JSON over HTTP; disabled when empty")
+	f.String("admin-listen", "", "address serving gRPC traces at /debug/requests and /debug/events; tracing is off when empty")
+	f.String("log-format", "text", "log format, text or json")
+	f.Bool("feature-register", true, "enable the Register RPC")
+	f.Bool("feature-delete-user", true, "enable the DeleteUser RPC")
+	f.Bool("feature-change-password", true, "enable the ChangePassword RPC")
+
+	for key, flag := range map[string]string{
+		"listen":                   "listen",
+		"username_index":           "username-index",
+		"dev":                      "dev",
+		"embedded_hydra":           "embedded-hydra",
+		"tls.cert_file":            "tls-cert",
+		"tls.key_file":             "tls-key",
+		"tls.client_ca_file":       "tls-client-ca",
+		"hydra.cluster_url":        "hydra-url",
+		"hydra.client_id":          "hydra-client-id",
+		"hydra.client_secret":      "hydra-client-secret",
+		"hydra.ca_bundle":          "hydra-ca-bundle",
+		"hydra.pins":               "hydra-pins",
+		"hydra.client_cert_file":   "hydra-client-cert",
+		"hydra.client_key_file":    "hydra-client-key",
+		"hydra.scopes":             "hydra-scopes",
+		"hydra.skip_tls_verify":    "hydra-skip-tls-verify",
+		"hydra.connect_timeout":    "hydra-connect-timeout",
+		"hydra.request_timeout":    "hydra-request-timeout",
+		"hydra.retry_timeout":      "hydra-retry-timeout",
+		"hydra.breaker_threshold":  "hydra-breaker-threshold",
+		"hydra.breaker_cooldown":   "hydra-breaker-cooldown",
+		"auth.enabled":             "auth",
+		"auth.public_methods":      "auth-public-methods",
+		"health.interval":          "health-interval",
+		"reflection":               "reflection",
+		"shutdown_timeout":         "shutdown-timeout",
+		"metrics_listen":           "metrics-listen",
+		"admin_listen":             "admin-listen",
+		"gateway_listen":           "gateway-listen",
+		"log.format":               "log-format",
+		"features.register":        "feature-register",
+		"features.delete_user":     "feature-delete-user",
+		"features.change_password": "feature-change-password",
+	} {
+		v.BindPFlag(key, f.Lookup(flag))
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+}
+
+// loadConfig reads the configuration file named by the --config flag, if
+// any, and resolves every setting.
+func loadConfig(cmd *cobra.Command, v *viper.Viper) (*config, error) {
+	if file, _ := cmd.Flags().GetString("config"); file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("could not read config file %s: %v", file, err)
+		}
+	}
+
+	c := &config{}
+	c.Listen = v.GetString("listen")
+	c.UsernameIndex = v.GetString("username_index")
+	c.Dev = v.GetBool("dev")
+	c.EmbeddedHydra = v.GetBool("embedded_hydra")
+	c.TLS.CertFile = v.GetString("tls.cert_file")
+	c.TLS.KeyFile = v.GetString("tls.key_file")
+	c.TLS.ClientCAFile = v.GetString("tls.client_ca_file")
+	c.Hydra.ClusterURL = v.GetString("hydra.cluster_url")
+	c.Hydra.ClientID = v.GetString("hydra.client_id")
+	c.Hydra.ClientSecret = v.GetString("hydra.client_secret")
+	c.Hydra.CABundle = v.GetString("hydra.ca_bundle")
+	c.Hydra.Pins = stringList(v.Get("hydra.pins"))
+	c.Hydra.ClientCertFile = v.GetString("hydra.client_cert_file")
+	c.Hydra.ClientKeyFile = v.GetString("hydra.client_key_file")
+	c.Hydra.Scopes = stringList(v.Get("hydra.scopes"))
+	c.Hydra.SkipTLSVerify = v.GetBool("hydra.skip_tls_verify")
+	c.Hydra.ConnectTimeout = v.GetDuration("hydra.connect_timeout")
+	c.Hydra.RequestTimeout = v.GetDuration("hydra.request_timeout")
+	c.Hydra.RetryTimeout = v.GetDuration("hydra.retry_timeout")
+	c.Hydra.BreakerThreshold = v.GetInt("hydra.breaker_threshold")
+	c.Hydra.BreakerCooldown = v.GetDuration("hydra.breaker_cooldown")
+	c.Auth.Enabled = v.GetBool("auth.enabled")
+	c.Auth.PublicMethods = stringList(v.Get("auth.public_methods"))
+	c.Health.Interval = v.GetDuration("health.interval")
+	c.Reflection = v.GetBool("reflection")
+	c.ShutdownTimeout = v.GetDuration("shutdown_timeout")
+	c.MetricsListen = v.GetString("metrics_listen")
+	c.AdminListen = v.GetString("admin_listen")
+	c.GatewayListen = v.GetString("gateway_listen")
+	c.Log.Format = v.GetString("log.format")
+	c.Features.Register = v.GetBool("features.register")
+	c.Features.DeleteUser = v.GetBool("features.delete_user")
+	c.Features.ChangePassword = v.GetBool("features.change_password")
+
+	return c, nil
+}
+
+// stringList accepts both lists from configuration files and comma or
+// space separated strings from flags and the environment.
+func stringList(val interface{}) []string {
+	var out []string
+	switch t := val.(type) {
+	case []interface{}:
+		for _, item := range t {
+			out = append(out, fmt.Sprint(item))
+		}
+	case []string:
+		out = t
+	case string:
+		out = strings.FieldsFunc(t, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	}
+	return out
+}
+
+// configErrors collects every problem found while validating a config.
+type configErrors []string
+
+func (e configErrors) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+// validate checks every setting and reports all problems at once.
+func (c *config) validate() error {
+	var errs configErrors
+	addf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+		addf("listen: %v", err)
+	}
+
+	if c.MetricsListen != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsListen); err != nil {
+			addf("metrics_listen: %v", err)
+		}
+	}
+
+	if c.AdminListen != "" {
+		if _, _, err := net.SplitHostPort(c.AdminListen); err != nil {
+			addf("admin_listen: %v", err)
+		}
+	}
+	if c.GatewayListen != "" {
+		if _, _, err := net.SplitHostPort(c.GatewayListen); err != nil {
+			addf("gateway_listen: %v", err)
+		}
+	}
+
+	if c.UsernameIndex != "" {
+		if fi, err := os.Stat(filepath.Dir(c.UsernameIndex)); err != nil || !fi.IsDir() {
+			addf("username_index: directory of %s does not exist", c.UsernameIndex)
+		}
+	}
+
+	switch {
+	case c.TLS.CertFile == "" && c.TLS.KeyFile == "":
+	case c.TLS.CertFile == "" || c.TLS.KeyFile == "":
+		addf("tls: cert_file and key_file must be set together")
+	default:
+		if _, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile); err != nil {
+			addf("tls: %v", err)
+		}
+	}
+	if c.TLS.ClientCAFile != "" {
+		if c.TLS.CertFile == "" {
+			addf("tls.client_ca_file: requires cert_file and key_file")
+		}
+		if _, err := loadCertPool(c.TLS.ClientCAFile); err != nil {
+			addf("tls.client_ca_file: %v", err)
+		}
+	}
+
+	if c.Dev && c.EmbeddedHydra {
+		addf("dev and embedded_hydra are mutually exclusive")
+	}
+	if !c.Dev && !c.EmbeddedHydra {
+		c.validateHydra(addf)
+	}
+
+	if c.Hydra.ConnectTimeout < 0 {
+		addf("hydra.connect_timeout: must not be negative")
+	}
+	if c.Hydra.RequestTimeout <= 0 {
+		addf("hydra.request_timeout: must be positive")
+	}
+	if c.Hydra.RetryTimeout < 0 {
+		addf("hydra.retry_timeout: must not be negative")
+	}
+	if c.Hydra.BreakerThreshold <= 0 {
+		addf("hydra.breaker_threshold: must be positive")
+	}
+	if c.Hydra.BreakerCooldown <= 0 {
+		addf("hydra.breaker_cooldown: must be positive")
+	}
+
+	if c.Health.Interval <= 0 {
+		addf("health.interval: must be positive")
+	}
+	if c.ShutdownTimeout < 0 {
+		addf("shutdown_timeout: must not be negative")
+	}
+
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		addf("log.format: %q is neither text nor json", c.Log.Format)
+	}
+
+	for _, name := range c.Auth.PublicMethods {
+		if _, ok := methodPermissions["/identity.Identity/"+name]; !ok {
+			addf("auth.public_methods: unknown method %q", name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateHydra checks the settings of the connection to hydra, which are
+// unused in development mode and with an embedded hydra.
+func (c *config) validateHydra(addf func(format string, args ...interface{})) {
+	if u, err := url.Parse(c.Hydra.ClusterURL); err != nil {
+		addf("hydra.cluster_url: %v", err)
+	} else if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		addf("hydra.cluster_url: %q is not an http(s) URL", c.Hydra.ClusterURL)
+	}
+	if c.Hydra.ClientID == "" {
+		addf("hydra.client_id: must be set")
+	}
+	if c.Hydra.ClientSecret == "" {
+		addf("hydra.client_secret: must be set")
+	}
+	if c.Hydra.CABundle != "" {
+		if _, err := loadCertPool(c.Hydra.CABundle); err != nil {
+			addf("hydra.ca_bundle: %v", err)
+		}
+	}
+	for _, p := range c.Hydra.Pins {
+		if b, err := hex.DecodeString(normalizeFingerprint(p)); err != nil || len(b) != sha256.Size {
+			addf("hydra.pins: %q is not a hex encoded SHA-256 fingerprint", p)
+		}
+	}
+	switch {
+	case c.Hydra.ClientCertFile == "" && c.Hydra.ClientKeyFile == "":
+	case c.Hydra.ClientCertFile == "" || c.Hydra.ClientKeyFile == "":
+		addf("hydra: client_cert_file and client_key_file must be set together")
+	default:
+		if _, err := tls.LoadX509KeyPair(c.Hydra.ClientCertFile, c.Hydra.ClientKeyFile); err != nil {
+			addf("hydra: %v", err)
+		}
+	}
+}
+
+// loadCertPool reads a PEM bundle of certificates into a pool.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s contains no PEM certificates", file)
+	}
+	return pool, nil
+}
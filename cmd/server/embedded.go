@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ory-am/hydra/client"
+	"github.com/ory-am/hydra/connection"
+	"github.com/ory-am/hydra/herodot"
+	"github.com/ory-am/hydra/jwk"
+	hoauth2 "github.com/ory-am/hydra/oauth2"
+	"github.com/ory-am/hydra/policy"
+	"github.com/ory-am/hydra/warden"
+)
+
+// serveEmbeddedHydra serves hydra's HTTP API over the managers of b on a
+// loopback listener and returns its URL. Only the client credentials grant is
+// enabled on the token endpoint.
+func serveEmbeddedHydra(b *memoryBackend) (string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	endpoint := "http://" + lis.Addr().String()
+
+	h := &herodot.JSON{}
+	router := httprouter.New()
+	(&client.Handler{H: h, W: b.warden, Manager: b.clients}).SetRoutes(router)
+	(&connection.Handler{H: h, W: b.warden, Manager: b.connections}).SetRoutes(router)
+	(&jwk.Handler{H: h, W: b.warden, Manager: b.keys}).SetRoutes(router)
+	(&policy.Handler{H: h, W: b.warden, Manager: b.policies}).SetRoutes(router)
+	(&warden.WardenHandler{H: h, Warden: b.warden}).SetRoutes(router)
+	(&hoauth2.Handler{
+		OAuth2: b.oauth2,
+		Consent: &hoauth2.DefaultConsentStrategy{
+			Issuer:                   memoryIssuer,
+			KeyManager:               b.keys,
+			DefaultIDTokenLifespan:   memoryTokenLifespan,
+			DefaultChallengeLifespan: memoryTokenLifespan,
+		},
+		ConsentURL:   url.URL{Scheme: "http", Host: lis.Addr().String(), Path: hoauth2.ConsentPath},
+		ForcedHTTP:   true,
+		Introspector: b.introspector,
+		Firewall:     b.warden,
+		H:            h,
+	}).SetRoutes(router)
+
+	go http.Serve(lis, router)
+	return endpoint, nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	goerrors "github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+	perrors "github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ory-am/hydra/herodot"
+	"github.com/ory-am/hydra/pkg"
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// errorDetailKey is the trailer that carries a marshalled pb.ErrorDetail.
+const errorDetailKey = "identity-error-detail-bin"
+
+// maxUpstreamMessage bounds how much of a hydra error body is passed on.
+const maxUpstreamMessage = 512
+
+// hydraStatusPattern extracts the HTTP status code from the errors returned by
+// hydra's HTTP managers, e.g. "Expected status code 200, got 404."
+var hydraStatusPattern = regexp.MustCompile(`got (\d{3})`)
+
+// errorInterceptor translates every error returned by a unary handler into a
+// gRPC status and attaches a pb.ErrorDetail trailer.
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	detail, err := translateError(err)
+	if md, merr := detailTrailer(detail); merr == nil {
+		grpc.SetTrailer(ctx, md)
+	}
+	return nil, err
+}
+
+// errorStreamInterceptor is the streaming counterpart of errorInterceptor.
+func errorStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	if err == nil {
+		return nil
+	}
+
+	detail, err := translateError(err)
+	if md, merr := detailTrailer(detail); merr == nil {
+		ss.SetTrailer(md)
+	}
+	return err
+}
+
+func detailTrailer(detail *pb.ErrorDetail) (metadata.MD, error) {
+	data, err := proto.Marshal(detail)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Pairs(errorDetailKey, string(data)), nil
+}
+
+// translateError turns err into a gRPC status error. Errors that already are
+// statuses keep their code; hydra, network and library errors are mapped to
+// the closest code. Anything else becomes Internal and is logged, since its
+// message may reveal server internals.
+func translateError(err error) (*pb.ErrorDetail, error) {
+	if code := grpc.Code(err); code != codes.Unknown {
+		return &pb.ErrorDetail{Reason: reason(code)}, err
+	}
+
+	for e := err; e != nil; e = unwrap(e) {
+		// context.DeadlineExceeded is a net.Error too, so it is matched
+		// first.
+		switch e {
+		case context.Canceled:
+			return &pb.ErrorDetail{Reason: reason(codes.Canceled)}, grpc.Errorf(codes.Canceled, "%v", e)
+		case context.DeadlineExceeded:
+			return &pb.ErrorDetail{Reason: reason(codes.DeadlineExceeded)}, grpc.Errorf(codes.DeadlineExceeded, "%v", e)
+		case pkg.ErrNotFound:
+			return &pb.ErrorDetail{Reason: "HYDRA_NOT_FOUND", UpstreamStatus: http.StatusNotFound},
+				grpc.Errorf(codes.NotFound, "not found")
+		}
+
+		switch t := e.(type) {
+		case *herodot.Error:
+			return statusError(t.Code, t.Error())
+		case net.Error:
+			if t.Timeout() {
+				return &pb.ErrorDetail{Reason: "HYDRA_TIMEOUT"},
+					grpc.Errorf(codes.Unavailable, "hydra did not answer in time")
+			}
+			return &pb.ErrorDetail{Reason: "HYDRA_UNAVAILABLE"},
+				grpc.Errorf(codes.Unavailable, "hydra is unreachable")
+		}
+	}
+
+	if upstreamNotFound(err) {
+		return &pb.ErrorDetail{
+			Reason:          "HYDRA_NOT_FOUND",
+			UpstreamStatus:  http.StatusInternalServerError,
+			UpstreamMessage: upstreamMessage(err),
+		}, grpc.Errorf(codes.NotFound, "not found")
+	}
+	// hydra answers many requests it rejects, such as a client with a short
+	// secret, with status 500 and the reason in the body. Only the reason is
+	// passed on, since hydra may echo the client, secret included, after it.
+	if msg := upstreamError(err); msg != "" {
+		code, detail := codes.InvalidArgument, &pb.ErrorDetail{
+			Reason:          "HYDRA_REJECTED",
+			UpstreamStatus:  http.StatusInternalServerError,
+			UpstreamMessage: msg,
+		}
+		if callerRejected(err) {
+			code, detail.Reason = codes.PermissionDenied, "HYDRA_UNAUTHORIZED"
+		}
+		return detail, grpc.Errorf(code, "hydra rejected the request: %s", msg)
+	}
+	if status := hydraStatus(err); status != 0 {
+		return statusError(status, upstreamMessage(err))
+	}
+
+	log.Printf("internal error: %v", err)
+	return &pb.ErrorDetail{Reason: reason(codes.Internal)}, grpc.Errorf(codes.Internal, "internal error")
+}
+
+// statusError maps an HTTP status returned by hydra to a gRPC status.
+func statusError(status int, message string) (*pb.ErrorDetail, error) {
+	detail := &pb.ErrorDetail{
+		UpstreamStatus:  int32(status),
+		UpstreamMessage: message,
+	}
+
+	var code codes.Code
+	switch {
+	case status == http.StatusBadRequest:
+		code, detail.Reason = codes.InvalidArgument, "HYDRA_BAD_REQUEST"
+	case status == http.StatusUnauthorized:
+		code, detail.Reason = codes.PermissionDenied, "HYDRA_UNAUTHORIZED"
+	case status == http.StatusForbidden:
+		code, detail.Reason = codes.PermissionDenied, "HYDRA_FORBIDDEN"
+	case status == http.StatusNotFound:
+		code, detail.Reason = codes.NotFound, "HYDRA_NOT_FOUND"
+	case status == http.StatusConflict:
+		code, detail.Reason = codes.AlreadyExists, "HYDRA_CONFLICT"
+	case status == http.StatusTooManyRequests:
+		code, detail.Reason = codes.ResourceExhausted, "HYDRA_RATE_LIMITED"
+	case status >= 500:
+		code, detail.Reason = codes.Unavailable, "HYDRA_UNAVAILABLE"
+	default:
+		code, detail.Reason = codes.Unknown, "HYDRA_UNEXPECTED_STATUS"
+	}
+
+	return detail, grpc.Errorf(code, "hydra answered with status %d", status)
+}
+
+// unwrap returns the error wrapped by err, or nil if there is none.
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case *goerrors.Error:
+		return e.Err
+	case *url.Error:
+		return e.Err
+	}
+
+	if cause := perrors.Cause(err); cause != err {
+		return cause
+	}
+	return nil
+}
+
+// hydraStatus returns the HTTP status code carried by an error from one of
+// hydra's HTTP managers, or 0 if there is none.
+func hydraStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	m := hydraStatusPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code
+}
+
+// isNotFound reports whether err means that hydra does not know the requested
+// object, as an HTTP 404 or as pkg.ErrNotFound from a memory manager.
+func isNotFound(err error) bool {
+	for e := err; e != nil; e = unwrap(e) {
+		if e == pkg.ErrNotFound {
+			return true
+		}
+	}
+	return hydraStatus(err) == http.StatusNotFound || upstreamNotFound(err)
+}
+
+// upstreamNotFound reports whether hydra answered with pkg.ErrNotFound. hydra
+// sends that with status 500 when its memory managers do not find an object.
+func upstreamNotFound(err error) bool {
+	return upstreamError(err) == pkg.ErrNotFound.Error()
+}
+
+// upstreamError returns the error hydra itself reported with status 500, or
+// "" if the response did not come from hydra's error handling, e.g. because a
+// proxy in front of hydra failed. Only the first JSON value of the body is
+// read, since some of hydra's handlers write their response after the error.
+func upstreamError(err error) string {
+	if hydraStatus(err) != http.StatusInternalServerError {
+		return ""
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(strings.NewReader(upstreamMessage(err))).Decode(&body) != nil {
+		return ""
+	}
+	return body.Error
+}
+
+// upstreamMessage returns the response body hydra's HTTP managers append to
+// their errors after the first line.
+func upstreamMessage(err error) string {
+	msg := err.Error()
+	i := strings.IndexByte(msg, '\n')
+	if i < 0 {
+		return ""
+	}
+	msg = strings.TrimSpace(msg[i+1:])
+	if len(msg) > maxUpstreamMessage {
+		msg = msg[:maxUpstreamMessage]
+	}
+	return msg
+}
+
+// reason turns a code such as AlreadyExists into ALREADY_EXISTS.
+func reason(code codes.Code) string {
+	var b []rune
+	for i, r := range code.String() {
+		if unicode.IsUpper(r) && i > 0 {
+			b = append(b, '_')
+		}
+		b = append(b, unicode.ToUpper(r))
+	}
+	return string(b)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+
+	goerrors "github.com/go-errors/errors"
+	perrors "github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory-am/hydra/herodot"
+	"github.com/ory-am/hydra/pkg"
+)
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// hydraError returns an error like the ones hydra's HTTP managers return for
+// a response with status and body.
+func hydraError(status int, body string) error {
+	return goerrors.Errorf("Expected 2xx status code but got %d.\n%s", status, body)
+}
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     codes.Code
+		reason   string
+		upstream int32
+	}{
+		{"status", grpc.Errorf(codes.AlreadyExists, "taken"), codes.AlreadyExists, "ALREADY_EXISTS", 0},
+		{"herodot error", herodot.ErrForbidden, codes.PermissionDenied, "HYDRA_FORBIDDEN", 403},
+		{"timeout", &url.Error{Op: "Post", URL: "http://hydra", Err: timeoutError{}}, codes.Unavailable, "HYDRA_TIMEOUT", 0},
+		{"unreachable", &url.Error{Op: "Post", URL: "http://hydra", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, codes.Unavailable, "HYDRA_UNAVAILABLE", 0},
+		{"canceled", context.Canceled, codes.Canceled, "CANCELED", 0},
+		{"deadline", perrors.Wrap(context.DeadlineExceeded, "calling hydra"), codes.DeadlineExceeded, "DEADLINE_EXCEEDED", 0},
+		{"memory manager not found", goerrors.New(pkg.ErrNotFound), codes.NotFound, "HYDRA_NOT_FOUND", 404},
+		{"hydra not found with status 500", hydraError(500, `{"error":"Not found","code":500}`), codes.NotFound, "HYDRA_NOT_FOUND", 500},
+		{"hydra rejection", hydraError(500, `{"error":"The client secret must be at least 6 characters long","code":500}`), codes.InvalidArgument, "HYDRA_REJECTED", 500},
+		{"hydra rejection followed by the client", hydraError(500, `{"error":"The client secret must be at least 6 characters long","code":500}{"id":"1","client_secret":"abc"}`), codes.InvalidArgument, "HYDRA_REJECTED", 500},
+		{"hydra unauthorized with status 500", hydraError(500, `{"error":"An error occurred: The request could not be authorized","code":500}`), codes.PermissionDenied, "HYDRA_UNAUTHORIZED", 500},
+		{"status 500 from a proxy", hydraError(500, "Internal Server Error"), codes.Unavailable, "HYDRA_UNAVAILABLE", 500},
+		{"status 502", hydraError(502, "Bad Gateway"), codes.Unavailable, "HYDRA_UNAVAILABLE", 502},
+		{"status 400", hydraError(400, ""), codes.InvalidArgument, "HYDRA_BAD_REQUEST", 400},
+		{"status 404", hydraError(404, ""), codes.NotFound, "HYDRA_NOT_FOUND", 404},
+		{"status 409", hydraError(409, ""), codes.AlreadyExists, "HYDRA_CONFLICT", 409},
+		{"status 429", hydraError(429, ""), codes.ResourceExhausted, "HYDRA_RATE_LIMITED", 429},
+		{"status 418", hydraError(418, ""), codes.Unknown, "HYDRA_UNEXPECTED_STATUS", 418},
+		{"other error", errors.New("open /etc/identity: permission denied"), codes.Internal, "INTERNAL", 0},
+	}
+
+	for _, test := range tests {
+		detail, err := translateError(test.err)
+		if got := grpc.Code(err); got != test.code {
+			t.Errorf("%s: got %v, want %v (%v)", test.name, got, test.code, err)
+		}
+		if detail.Reason != test.reason {
+			t.Errorf("%s: got reason %s, want %s", test.name, detail.Reason, test.reason)
+		}
+		if detail.UpstreamStatus != test.upstream {
+			t.Errorf("%s: got upstream status %d, want %d", test.name, detail.UpstreamStatus, test.upstream)
+		}
+		if strings.Contains(detail.UpstreamMessage, "client_secret") || strings.Contains(grpc.ErrorDesc(err), "client_secret") {
+			t.Errorf("%s: secret passed on in %v / %q", test.name, err, detail.UpstreamMessage)
+		}
+	}
+}
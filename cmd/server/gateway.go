@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pborman/uuid"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// httpStatus maps gRPC codes to the HTTP statuses the gateway answers with.
+var httpStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+}
+
+// maxGatewayBody is the largest request body the gateway reads.
+const maxGatewayBody = 1 << 20
+
+var (
+	gatewayMarshaler   = &jsonpb.Marshaler{OrigName: true}
+	gatewayUnmarshaler = &jsonpb.Unmarshaler{}
+)
+
+// gateway exposes the Identity service as JSON over HTTP. Calls go through
+// the same interceptors as gRPC calls, with the Authorization and
+// X-Request-ID headers passed on as metadata.
+type gateway struct {
+	srv       pb.IdentityServer
+	intercept grpc.UnaryServerInterceptor
+}
+
+// serveGateway serves g on addr, over TLS with the gRPC server's certificates
+// if certs is not nil.
+func serveGateway(addr string, g *gateway, certs *certReloader) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if certs != nil {
+		lis = tls.NewListener(lis, &tls.Config{
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return certs.config(), nil
+			},
+		})
+	}
+
+	hs := &http.Server{Handler: g.handler()}
+	go func() {
+		if err := hs.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Printf("gateway stopped: %v", err)
+		}
+	}()
+	return hs, nil
+}
+
+// handler returns the gateway's routes.
+func (g *gateway) handler() http.Handler {
+	router := httprouter.New()
+
+	router.POST("/v1/users", g.handle("Register", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.RegisterRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.Register(ctx, req.(*pb.RegisterRequest))
+	}))
+
+	router.GET("/v1/users", g.handle("ListUsers", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		q := r.URL.Query()
+		req := &pb.ListUsersRequest{PageToken: q.Get("page_token"), UsernamePrefix: q.Get("username_prefix")}
+		if size := q.Get("page_size"); size != "" {
+			n, err := strconv.ParseInt(size, 10, 32)
+			if err != nil {
+				return nil, grpc.Errorf(codes.InvalidArgument, "page_size %q is not a number", size)
+			}
+			req.PageSize = int32(n)
+		}
+		return req, nil
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.ListUsers(ctx, req.(*pb.ListUsersRequest))
+	}))
+
+	router.GET("/v1/users/:id", g.handle("GetUser", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		return &pb.GetUserRequest{Id: ps.ByName("id")}, nil
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.GetUser(ctx, req.(*pb.GetUserRequest))
+	}))
+
+	router.DELETE("/v1/users/:id", g.handle("DeleteUser", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		return &pb.DeleteUserRequest{Id: ps.ByName("id")}, nil
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.DeleteUser(ctx, req.(*pb.DeleteUserRequest))
+	}))
+
+	router.POST("/v1/users/:id/password", g.handle("ChangePassword", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.ChangePasswordRequest{}
+		if err := decodeBody(r, req); err != nil {
+			return nil, err
+		}
+		req.Id = ps.ByName("id")
+		return req, nil
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.ChangePassword(ctx, req.(*pb.ChangePasswordRequest))
+	}))
+
+	router.POST("/v1/login", g.handle("Login", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.LoginRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.Login(ctx, req.(*pb.LoginRequest))
+	}))
+
+	router.POST("/v1/tokens/introspect", g.handle("IntrospectToken", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.IntrospectTokenRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.IntrospectToken(ctx, req.(*pb.IntrospectTokenRequest))
+	}))
+
+	router.POST("/v1/tokens/validate", g.handle("ValidateToken", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.ValidateTokenRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.ValidateToken(ctx, req.(*pb.ValidateTokenRequest))
+	}))
+
+	router.POST("/v1/tokens/allowed", g.handle("TokenAllowed", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.TokenAllowedRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.TokenAllowed(ctx, req.(*pb.TokenAllowedRequest))
+	}))
+
+	router.POST("/v1/allowed", g.handle("IsAllowed", func(r *http.Request, ps httprouter.Params) (proto.Message, error) {
+		req := &pb.AccessRequest{}
+		return req, decodeBody(r, req)
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.srv.IsAllowed(ctx, req.(*pb.AccessRequest))
+	}))
+
+	return router
+}
+
+// handle returns a route that builds a request with decode and calls the
+// method through the interceptors.
+func (g *gateway) handle(method string, decode func(*http.Request, httprouter.Params) (proto.Message, error), call grpc.UnaryHandler) httprouter.Handle {
+	info := &grpc.UnaryServerInfo{Server: g.srv, FullMethod: "/identity.Identity/" + method}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = uuid.New()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxGatewayBody)
+		req, err := decode(r, ps)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		md := metadata.Pairs(requestIDHeader, id)
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			md["authorization"] = []string{auth}
+		}
+		ctx := metadata.NewContext(r.Context(), md)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: httpPeer(r.RemoteAddr)})
+
+		resp, err := g.intercept(ctx, req, info, call)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := gatewayMarshaler.Marshal(w, resp.(proto.Message)); err != nil {
+			writeError(w, err)
+		}
+	}
+}
+
+// decodeBody reads a JSON request body into req.
+func decodeBody(r *http.Request, req proto.Message) error {
+	if err := gatewayUnmarshaler.Unmarshal(r.Body, req); err != nil {
+		return grpc.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// writeError answers with the HTTP status for err's gRPC code and a JSON body
+// holding the code and message.
+func writeError(w http.ResponseWriter, err error) {
+	code := grpc.Code(err)
+	status, ok := httpStatus[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{reason(code), grpc.ErrorDesc(err)})
+}
+
+// httpPeer is the address of an HTTP client, reported to the interceptors as
+// the peer of the call.
+type httpPeer string
+
+var _ net.Addr = httpPeer("")
+
+func (a httpPeer) Network() string { return "tcp" }
+func (a httpPeer) String() string  { return string(a) }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// identityService is the name the Identity service reports its health under.
+const identityService = "identity.Identity"
+
+// healthServer is health.Server, except that the status of the server as a
+// whole, asked for with an empty service name, is that of the Identity
+// service. health.Server always reports SERVING for it.
+type healthServer struct {
+	*health.Server
+
+	mu           sync.Mutex
+	shuttingDown bool
+}
+
+func newHealthServer() *healthServer {
+	h := &healthServer{Server: health.NewServer()}
+	h.SetServingStatus(identityService, healthpb.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+// setServing sets the status of the Identity service, unless the server is
+// shutting down.
+func (h *healthServer) setServing(serving bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shuttingDown {
+		return
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	h.SetServingStatus(identityService, status)
+}
+
+// Shutdown reports the Identity service as NOT_SERVING from now on.
+func (h *healthServer) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.shuttingDown = true
+	h.SetServingStatus(identityService, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service == "" {
+		req = &healthpb.HealthCheckRequest{Service: identityService}
+	}
+	return h.Server.Check(ctx, req)
+}
+
+// watchHydra probes hydra every interval until stop is closed, and reports
+// the Identity service as NOT_SERVING while hydra can not be reached.
+func watchHydra(b backend, h *healthServer, interval time.Duration, stop <-chan struct{}) {
+	serving := false
+	for {
+		err := probeHydra(b, interval)
+		switch {
+		case err == nil && !serving:
+			log.Printf("hydra is reachable, serving")
+			h.setServing(true)
+		case err != nil && serving:
+			log.Printf("hydra is unreachable, not serving: %v", err)
+			h.setServing(false)
+		}
+		serving = err == nil
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// probeHydra pings hydra and gives up after timeout.
+func probeHydra(b backend, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return b.Ping(ctx)
+}
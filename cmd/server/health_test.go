@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestProbeHydra(t *testing.T) {
+	release := make(chan struct{})
+	hydra := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clients/identity":
+			w.Write([]byte(`{"id":"identity"}`))
+		case "/clients/hanging":
+			<-release
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer hydra.Close()
+	defer close(release)
+	endpoint, err := url.Parse(hydra.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		clientID string
+		ok       bool
+	}{
+		{"identity", true},
+		{"unknown", false},
+		{"hanging", false},
+	}
+
+	for _, test := range tests {
+		b := &sdkBackend{endpoint: endpoint, clientID: test.clientID, authenticated: http.DefaultClient}
+		start := time.Now()
+		err := probeHydra(b, 100*time.Millisecond)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got error %v, want ok %v", test.clientID, err, test.ok)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: probe took %v, want at most the timeout", test.clientID, elapsed)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/ory-am/hydra/pkg"
+)
+
+// connectHydra does what sdk.Connect does, but over a transport that uses the
+// configured CA bundle, certificate pins and client certificate, none of
+// which sdk.Connect has options for. Every request to hydra times out after
+// c.Hydra.RequestTimeout, and connecting gives up at deadline unless it is
+// zero.
+func connectHydra(c *config, deadline time.Time) (*sdkBackend, error) {
+	endpoint, err := url.Parse(c.Hydra.ClusterURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := hydraTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: c.Hydra.RequestTimeout,
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     c.Hydra.ClientID,
+		ClientSecret: c.Hydra.ClientSecret,
+		TokenURL:     pkg.JoinURL(endpoint, "oauth2/token").String(),
+		Scopes:       c.Hydra.Scopes,
+	}
+
+	fetch := func(hc *http.Client) func() (*oauth2.Token, error) {
+		ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, hc)
+		return func() (*oauth2.Token, error) {
+			return credentials.Token(ctx)
+		}
+	}
+
+	// Fetch a token right away so bad credentials fail at startup, and give
+	// up at deadline even if hydra never answers.
+	first := *base
+	if !deadline.IsZero() {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return nil, errors.New("hydra did not answer before the connect timeout")
+		}
+		if left < first.Timeout {
+			first.Timeout = left
+		}
+	}
+	source := &renewingTokenSource{fetch: fetch(&first)}
+	if _, err := source.Token(); err != nil {
+		return nil, err
+	}
+	source.fetch = fetch(base)
+
+	authenticated := &http.Client{
+		Transport: &tokenTransport{source: source, base: base.Transport},
+		Timeout:   base.Timeout,
+	}
+
+	return &sdkBackend{endpoint: endpoint, clientID: c.Hydra.ClientID, authenticated: authenticated, http: base}, nil
+}
+
+// renewingTokenFraction is the part of a token's lifetime after which the
+// server fetches a new one.
+const renewingTokenFraction = 0.9
+
+// renewingTokenSource hands out the server's token for hydra, and fetches a
+// new one when most of its lifetime has passed or hydra rejected it.
+type renewingTokenSource struct {
+	fetch func() (*oauth2.Token, error)
+
+	mu       sync.Mutex
+	token    *oauth2.Token
+	renewAt  time.Time
+	rejected bool
+}
+
+func (s *renewingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && (s.renewAt.IsZero() || time.Now().Before(s.renewAt)) {
+		return s.token, nil
+	}
+
+	reason := "expiring"
+	switch {
+	case s.rejected:
+		reason = "rejected"
+	case s.token == nil:
+		reason = "initial"
+	}
+
+	token, err := s.fetch()
+	if err != nil {
+		hydraTokenFetches.Inc(reason, "error")
+		return nil, err
+	}
+	hydraTokenFetches.Inc(reason, "ok")
+	s.token = token
+	s.rejected = false
+	s.renewAt = time.Time{}
+	if !token.Expiry.IsZero() {
+		lifetime := time.Until(token.Expiry)
+		s.renewAt = time.Now().Add(time.Duration(float64(lifetime) * renewingTokenFraction))
+		hydraTokenExpiry.Set(float64(token.Expiry.Unix()))
+	}
+	return token, nil
+}
+
+// invalidate makes the next call to Token fetch a new token, unless another
+// caller has already replaced the rejected one.
+func (s *renewingTokenSource) invalidate(rejected *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == rejected {
+		s.token = nil
+		s.rejected = true
+	}
+}
+
+// tokenTransport authenticates requests to hydra with the server's token.
+// When hydra answers 401, it fetches a new token and retries once.
+type tokenTransport struct {
+	source *renewingTokenSource
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(withToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.source.invalidate(token)
+	token, err = t.source.Token()
+	if err != nil {
+		log.Printf("hydra rejected the server's token and a new one could not be fetched: %v", err)
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retry := withToken(req, token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// withToken returns a copy of req authenticated with token, since a
+// RoundTripper must not modify the request it is given.
+func withToken(req *http.Request, token *oauth2.Token) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	token.SetAuthHeader(r)
+	return r
+}
+
+// hydraTLSConfig builds the TLS configuration for connections to hydra.
+//
+// With a CA bundle, hydra's certificate must chain up to one of its CAs;
+// otherwise the system roots are used. Pins additionally require one of the
+// certificates in the verified chain to have a pinned SHA-256 fingerprint.
+// Pins without a CA bundle replace chain verification altogether, which suits
+// hydra's self-signed development certificates; hydra's own certificate must
+// then be pinned.
+func hydraTLSConfig(c *config) (*tls.Config, error) {
+	pins := make(map[string]bool, len(c.Hydra.Pins))
+	for _, p := range c.Hydra.Pins {
+		pins[normalizeFingerprint(p)] = true
+	}
+
+	tlsConfig := &tls.Config{}
+	switch {
+	case c.Hydra.CABundle != "":
+		pool, err := loadCertPool(c.Hydra.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	case len(pins) > 0:
+		tlsConfig.InsecureSkipVerify = true
+	case c.Hydra.SkipTLSVerify:
+		log.Printf("not verifying hydra's certificate, use a CA bundle or pins instead")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if len(pins) > 0 {
+		tlsConfig.VerifyPeerCertificate = pinVerifier(pins, c.Hydra.CABundle != "")
+	}
+
+	if c.Hydra.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.Hydra.ClientCertFile, c.Hydra.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// pinVerifier returns a VerifyPeerCertificate function that requires a
+// certificate with one of the pinned SHA-256 fingerprints. When chained is
+// set the certificates come from the verified chains. Otherwise nothing was
+// verified, and only the leaf counts: anyone can present hydra's public
+// certificate after their own.
+func pinVerifier(pins map[string]bool, chained bool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		var candidates [][]byte
+		if chained {
+			for _, chain := range verifiedChains {
+				for _, cert := range chain {
+					candidates = append(candidates, cert.Raw)
+				}
+			}
+		} else if len(rawCerts) > 0 {
+			candidates = rawCerts[:1]
+		}
+
+		for _, raw := range candidates {
+			sum := sha256.Sum256(raw)
+			if pins[hex.EncodeToString(sum[:])] {
+				return nil
+			}
+		}
+		return errors.New("hydra presented no certificate matching a pinned fingerprint")
+	}
+}
+
+// normalizeFingerprint turns "AB:CD:..." and "abcd..." into the same form.
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.Replace(fp, ":", "", -1))
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCert returns a certificate for name signed by parent, or self-signed if
+// parent is nil.
+func testCert(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func fingerprint(cert tls.Certificate) string {
+	sum := sha256.Sum256(cert.Certificate[0])
+	return hex.EncodeToString(sum[:])
+}
+
+// handshake connects a client using config to a server presenting chain.
+func handshake(config *tls.Config, chain tls.Certificate) error {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go tls.Server(server, &tls.Config{Certificates: []tls.Certificate{chain}}).Handshake()
+	return tls.Client(client, config).Handshake()
+}
+
+func TestPinVerifier(t *testing.T) {
+	hydra := testCert(t, "hydra", nil)
+	attacker := testCert(t, "attacker", nil)
+	ca := testCert(t, "ca", nil)
+	signed := testCert(t, "hydra", &ca)
+
+	tests := []struct {
+		name    string
+		pins    []string
+		chained bool
+		raw     [][]byte
+		chains  [][]*x509.Certificate
+		ok      bool
+	}{
+		{
+			name: "pinned leaf",
+			pins: []string{fingerprint(hydra)},
+			raw:  [][]byte{hydra.Certificate[0]},
+			ok:   true,
+		},
+		{
+			name: "pinned certificate after an unpinned leaf",
+			pins: []string{fingerprint(hydra)},
+			raw:  [][]byte{attacker.Certificate[0], hydra.Certificate[0]},
+		},
+		{
+			name: "no certificates",
+			pins: []string{fingerprint(hydra)},
+		},
+		{
+			name:    "pinned CA in the verified chain",
+			pins:    []string{fingerprint(ca)},
+			chained: true,
+			raw:     [][]byte{signed.Certificate[0]},
+			chains:  [][]*x509.Certificate{{signed.Leaf, ca.Leaf}},
+			ok:      true,
+		},
+		{
+			name:    "pinned certificate presented but not verified",
+			pins:    []string{fingerprint(hydra)},
+			chained: true,
+			raw:     [][]byte{signed.Certificate[0], hydra.Certificate[0]},
+			chains:  [][]*x509.Certificate{{signed.Leaf, ca.Leaf}},
+		},
+	}
+
+	for _, test := range tests {
+		pins := map[string]bool{}
+		for _, p := range test.pins {
+			pins[p] = true
+		}
+		err := pinVerifier(pins, test.chained)(test.raw, test.chains)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got error %v, want ok %v", test.name, err, test.ok)
+		}
+	}
+}
+
+// TestHydraTLSConfigPinnedLeaf checks that a server can not pass the pin
+// check by sending hydra's public certificate after its own.
+func TestHydraTLSConfigPinnedLeaf(t *testing.T) {
+	hydra := testCert(t, "hydra", nil)
+	attacker := testCert(t, "attacker", nil)
+
+	c := &config{}
+	c.Hydra.Pins = []string{fingerprint(hydra)}
+	tlsConfig, err := hydraTLSConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handshake(tlsConfig, hydra); err != nil {
+		t.Errorf("handshake with hydra's certificate: %v", err)
+	}
+
+	spoofed := attacker
+	spoofed.Certificate = [][]byte{attacker.Certificate[0], hydra.Certificate[0]}
+	if err := handshake(tlsConfig, spoofed); err == nil {
+		t.Error("handshake with another leaf followed by hydra's certificate succeeded")
+	}
+}
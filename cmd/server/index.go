@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ory-am/hydra/client"
+)
+
+var errUsernameTaken = errors.New("username is already taken")
+
+// usernameIndex maps usernames to the ID of the hydra client that owns them.
+// Implementations must be safe for concurrent use.
+type usernameIndex interface {
+	// Lookup returns the client ID registered for username.
+	Lookup(username string) (id string, ok bool)
+
+	// Reserve atomically assigns username to id, failing with
+	// errUsernameTaken if it already belongs to another client.
+	Reserve(username, id string) error
+
+	// Release removes username from the index.
+	Release(username string) error
+
+	// Reset replaces the whole index with entries.
+	Reset(entries map[string]string) error
+}
+
+type memoryIndex struct {
+	sync.RWMutex
+	entries map[string]string
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{entries: map[string]string{}}
+}
+
+func (m *memoryIndex) Lookup(username string) (string, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	id, ok := m.entries[username]
+	return id, ok
+}
+
+func (m *memoryIndex) Reserve(username, id string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if owner, ok := m.entries[username]; ok && owner != id {
+		return errUsernameTaken
+	}
+	m.entries[username] = id
+	return nil
+}
+
+func (m *memoryIndex) Release(username string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.entries, username)
+	return nil
+}
+
+func (m *memoryIndex) Reset(entries map[string]string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries = make(map[string]string, len(entries))
+	for k, v := range entries {
+		m.entries[k] = v
+	}
+	return nil
+}
+
+// fileIndex is a memoryIndex that writes itself to a JSON file after every
+// change.
+type fileIndex struct {
+	memoryIndex
+	path string
+}
+
+func newFileIndex(path string) (*fileIndex, error) {
+	f := &fileIndex{
+		memoryIndex: memoryIndex{entries: map[string]string{}},
+		path:        path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &f.entries); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileIndex) Reserve(username, id string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	owner, ok := f.entries[username]
+	if ok && owner != id {
+		return errUsernameTaken
+	}
+	f.entries[username] = id
+	if err := f.save(); err != nil {
+		if !ok {
+			delete(f.entries, username)
+		}
+		return err
+	}
+	return nil
+}
+
+func (f *fileIndex) Release(username string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	delete(f.entries, username)
+	return f.save()
+}
+
+func (f *fileIndex) Reset(entries map[string]string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.entries = make(map[string]string, len(entries))
+	for k, v := range entries {
+		f.entries[k] = v
+	}
+	return f.save()
+}
+
+// save writes the index to a temporary file and renames it into place so a
+// crash never leaves a truncated index behind. The caller must hold the lock.
+func (f *fileIndex) save() error {
+	data, err := json.MarshalIndent(f.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// rebuildIndex fills index from the clients currently stored in hydra. If
+// several clients share a name, the one with the lowest ID wins and the
+// others are returned as conflicts.
+func rebuildIndex(index usernameIndex, clients map[string]client.Client) (conflicts []string, err error) {
+	ids := make([]string, 0, len(clients))
+	for id := range clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	entries := make(map[string]string, len(clients))
+	for _, id := range ids {
+		name := clients[id].Name
+		if name == "" {
+			continue
+		}
+		if _, ok := entries[name]; ok {
+			conflicts = append(conflicts, id)
+			continue
+		}
+		entries[name] = id
+	}
+
+	return conflicts, index.Reset(entries)
+}
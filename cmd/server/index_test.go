@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ory-am/hydra/client"
+)
+
+func TestRebuildIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		clients   map[string]client.Client
+		entries   map[string]string
+		conflicts []string
+	}{
+		{
+			name:    "no clients",
+			entries: map[string]string{},
+		},
+		{
+			name: "unique names",
+			clients: map[string]client.Client{
+				"1": {ID: "1", Name: "alice"},
+				"2": {ID: "2", Name: "bob"},
+			},
+			entries: map[string]string{"alice": "1", "bob": "2"},
+		},
+		{
+			name: "unnamed client",
+			clients: map[string]client.Client{
+				"1": {ID: "1", Name: "alice"},
+				"2": {ID: "2"},
+			},
+			entries: map[string]string{"alice": "1"},
+		},
+		{
+			name: "shared name",
+			clients: map[string]client.Client{
+				"3": {ID: "3", Name: "alice"},
+				"1": {ID: "1", Name: "alice"},
+				"2": {ID: "2", Name: "alice"},
+			},
+			entries:   map[string]string{"alice": "1"},
+			conflicts: []string{"2", "3"},
+		},
+	}
+
+	for _, test := range tests {
+		index := newMemoryIndex()
+		index.Reserve("stale", "0")
+
+		conflicts, err := rebuildIndex(index, test.clients)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(conflicts, test.conflicts) {
+			t.Errorf("%s: got conflicts %v, want %v", test.name, conflicts, test.conflicts)
+		}
+		if !reflect.DeepEqual(index.entries, test.entries) {
+			t.Errorf("%s: got entries %v, want %v", test.name, index.entries, test.entries)
+		}
+	}
+}
+
+func TestFileIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "usernames.json")
+
+	f, err := newFileIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		err  error
+	}{
+		{"reserve alice", func() error { return f.Reserve("alice", "1") }, nil},
+		{"reserve alice again", func() error { return f.Reserve("alice", "1") }, nil},
+		{"reserve alice for another client", func() error { return f.Reserve("alice", "2") }, errUsernameTaken},
+		{"reserve bob", func() error { return f.Reserve("bob", "2") }, nil},
+		{"release bob", func() error { return f.Release("bob") }, nil},
+		{"reserve carol", func() error { return f.Reserve("carol", "3") }, nil},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != step.err {
+			t.Fatalf("%s: got error %v, want %v", step.name, err, step.err)
+		}
+	}
+
+	want := map[string]string{"alice": "1", "carol": "3"}
+	reopened, err := newFileIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reopened.entries, want) {
+		t.Errorf("reopened index holds %v, want %v", reopened.entries, want)
+	}
+
+	if err := reopened.Reset(map[string]string{"dave": "4"}); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = newFileIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := reopened.Lookup("dave"); !ok || id != "4" || len(reopened.entries) != 1 {
+		t.Errorf("index after reset holds %v, want only dave", reopened.entries)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files next to the index, want only the index", len(files))
+	}
+}
+
+func TestFileIndexMissing(t *testing.T) {
+	f, err := newFileIndex(filepath.Join(os.TempDir(), "does-not-exist", "usernames.json"))
+	if err != nil {
+		t.Fatalf("opening a missing index: %v", err)
+	}
+	if len(f.entries) != 0 {
+		t.Errorf("missing index holds %v", f.entries)
+	}
+	if err := f.Reserve("alice", "1"); err == nil {
+		t.Error("reserving in an index whose directory is missing succeeded")
+	}
+	if _, ok := f.Lookup("alice"); ok {
+		t.Error("failed reservation left alice in the index")
+	}
+}
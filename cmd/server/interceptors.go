@@ -0,0 +1,55 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// chainUnaryInterceptors combines interceptors into one, since a grpc.Server
+// accepts only a single unary interceptor. The first interceptor is the
+// outermost.
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStreamInterceptors is the streaming counterpart of
+// chainUnaryInterceptors.
+func chainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, inner)
+			}
+		}
+		return next(srv, ss)
+	}
+}
+
+// featureInterceptor rejects calls to methods that are switched off in c.
+func featureInterceptor(c *config) grpc.UnaryServerInterceptor {
+	disabled := map[string]bool{
+		"/identity.Identity/Register":       !c.Features.Register,
+		"/identity.Identity/DeleteUser":     !c.Features.DeleteUser,
+		"/identity.Identity/ChangePassword": !c.Features.ChangePassword,
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if disabled[info.FullMethod] {
+			return nil, grpc.Errorf(codes.Unimplemented, "%s is disabled on this server", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pborman/uuid"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// requestIDHeader is the metadata key that carries a call's request ID. One is
+// generated when the caller sends none, and unary calls return it in their
+// response headers.
+const requestIDHeader = "x-request-id"
+
+// secretFields are request fields, by proto name, that are never logged.
+// Any field whose name mentions a password or secret is left out as well.
+var secretFields = map[string]bool{
+	"token":        true,
+	"access_token": true,
+}
+
+// newLogger returns a logger writing in the given format, "text" or "json".
+// The standard library's logger writes through it too, and logrus' own
+// logger, which hydra's in-process components use, takes on the format.
+func newLogger(format string) *logrus.Logger {
+	logger := logrus.New()
+	if format == "json" {
+		logger.Formatter = &logrus.JSONFormatter{}
+	} else {
+		logger.Formatter = &logrus.TextFormatter{FullTimestamp: true}
+	}
+	logrus.SetFormatter(logger.Formatter)
+
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{logger})
+	return logger
+}
+
+// stdLogWriter lets the standard library's logger write through a logrus
+// logger, so that the server logs in one format.
+type stdLogWriter struct {
+	logger *logrus.Logger
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.logger.Info(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// callLogKey is the context key of a call's *callLog.
+type callLogKey struct{}
+
+// callLog collects what inner interceptors learn about a call for the
+// logging interceptor.
+type callLog struct {
+	subject string
+}
+
+// logSubject records the authenticated subject of the call in ctx.
+func logSubject(ctx context.Context, subject string) {
+	if l, ok := ctx.Value(callLogKey{}).(*callLog); ok {
+		l.subject = subject
+	}
+}
+
+// requestIDKey is the context key of a call's request ID.
+type requestIDKey struct{}
+
+// requestID returns the request ID of the call in ctx, or "" if there is none.
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// loggingInterceptor logs every unary call once it has finished, including
+// its request with secrets left out.
+func loggingInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, l, entry := startCall(ctx, logger, info.FullMethod)
+		grpc.SendHeader(ctx, metadata.Pairs(requestIDHeader, requestID(ctx)))
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if msg, ok := req.(proto.Message); ok {
+			entry = entry.WithField("request", redact(msg))
+		}
+		finishCall(entry, l, start, err)
+		return resp, err
+	}
+}
+
+// loggingStreamInterceptor is the streaming counterpart of
+// loggingInterceptor. Streamed messages are not logged.
+func loggingStreamInterceptor(logger *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, l, entry := startCall(ss.Context(), logger, info.FullMethod)
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		finishCall(entry, l, start, err)
+		return err
+	}
+}
+
+// startCall assigns the call a request ID and prepares its log entry.
+func startCall(ctx context.Context, logger *logrus.Logger, method string) (context.Context, *callLog, *logrus.Entry) {
+	var id string
+	if md, ok := metadata.FromContext(ctx); ok && len(md[requestIDHeader]) > 0 {
+		id = md[requestIDHeader][0]
+	} else {
+		id = uuid.New()
+	}
+
+	fields := logrus.Fields{"method": method, "request_id": id}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields["peer"] = p.Addr.String()
+	}
+
+	l := &callLog{}
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	ctx = context.WithValue(ctx, callLogKey{}, l)
+	return ctx, l, logger.WithFields(fields)
+}
+
+func finishCall(entry *logrus.Entry, l *callLog, start time.Time, err error) {
+	code := grpc.Code(err)
+	entry = entry.WithFields(logrus.Fields{
+		"code":    code.String(),
+		"latency": time.Since(start).String(),
+	})
+	if l.subject != "" {
+		entry = entry.WithField("subject", l.subject)
+	}
+
+	switch code {
+	case codes.OK:
+		entry.Info("call finished")
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		entry.WithField("error", grpc.ErrorDesc(err)).Error("call failed")
+	default:
+		entry.WithField("error", grpc.ErrorDesc(err)).Warn("call failed")
+	}
+}
+
+// redact renders msg as JSON fields, keyed by proto name, without secrets.
+func redact(msg proto.Message) interface{} {
+	data, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(msg)
+	if err != nil {
+		return fmt.Sprintf("unprintable %T", msg)
+	}
+	var fields interface{}
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return fmt.Sprintf("unprintable %T", msg)
+	}
+	return redactValue(fields)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for name, field := range t {
+			if isSecretField(name) {
+				t[name] = "REDACTED"
+			} else {
+				t[name] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = redactValue(item)
+		}
+	}
+	return v
+}
+
+func isSecretField(name string) bool {
+	return secretFields[name] || strings.Contains(name, "password") || strings.Contains(name, "secret")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want interface{}
+	}{
+		{
+			name: "register",
+			msg:  &pb.RegisterRequest{Username: "alice", Password: "secret1", Scope: "read"},
+			want: map[string]interface{}{"username": "alice", "password": "REDACTED", "scope": "read"},
+		},
+		{
+			name: "change password",
+			msg:  &pb.ChangePasswordRequest{Id: "1", OldPassword: "secret1", NewPassword: "secret2", RevokeTokens: true},
+			want: map[string]interface{}{"id": "1", "old_password": "REDACTED", "new_password": "REDACTED", "revoke_tokens": true},
+		},
+		{
+			name: "login response",
+			msg:  &pb.LoginResponse{AccessToken: "abc", TokenType: "bearer"},
+			want: map[string]interface{}{"access_token": "REDACTED", "token_type": "bearer"},
+		},
+		{
+			name: "nested",
+			msg: &pb.TokenAllowedRequest{
+				Token:   "abc",
+				Request: &pb.AccessRequest{Subject: "alice", Context: map[string]string{"client_secret": "x", "ip": "127.0.0.1"}},
+				Scopes:  []string{"read"},
+			},
+			want: map[string]interface{}{
+				"token": "REDACTED",
+				"request": map[string]interface{}{
+					"subject": "alice",
+					"context": map[string]interface{}{"client_secret": "REDACTED", "ip": "127.0.0.1"},
+				},
+				"scopes": []interface{}{"read"},
+			},
+		},
+		{
+			name: "empty",
+			msg:  &pb.ListUsersRequest{},
+			want: map[string]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		if got := redact(test.msg); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
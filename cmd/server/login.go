@@ -0,0 +1,98 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// tokenStatusPattern extracts the HTTP status code from the errors returned by
+// the oauth2 package, e.g. "oauth2: cannot fetch token: 401 Unauthorized".
+var tokenStatusPattern = regexp.MustCompile(`cannot fetch token: (\d{3})`)
+
+func (s *server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.Username == "" || req.Password == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "username and password must not be empty")
+	}
+
+	id, ok := s.usernames.Lookup(req.Username)
+	if !ok {
+		return nil, grpc.Errorf(codes.Unauthenticated, "invalid username or password")
+	}
+
+	// Tokens issued in the second in which the user's tokens were revoked
+	// count as revoked too, so a login right after it waits for that
+	// second to pass.
+	if wait := time.Until(s.revoked.Cutoff(id)); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	token, err := s.backend.Token(ctx, id, req.Password, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.LoginResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Scopes:      req.Scopes,
+	}
+	if !token.Expiry.IsZero() {
+		res.ExpiresAt = token.Expiry.Unix()
+	}
+	if scope, ok := token.Extra("scope").(string); ok {
+		res.Scopes = strings.Fields(scope)
+	}
+
+	return res, nil
+}
+
+// tokenError maps a failed token request, made with ctx, to a gRPC status.
+func tokenError(ctx context.Context, err error) error {
+	switch e := ctx.Err(); e {
+	case context.Canceled:
+		return grpc.Errorf(codes.Canceled, "%v", e)
+	case context.DeadlineExceeded:
+		return grpc.Errorf(codes.DeadlineExceeded, "%v", e)
+	}
+
+	m := tokenStatusPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return grpc.Errorf(codes.Unavailable, "token endpoint unreachable: %v", err)
+	}
+
+	status, _ := strconv.Atoi(m[1])
+	if e := oauthError(status, err.Error()); e != nil {
+		return e
+	}
+	return err
+}
+
+// oauthError maps a token endpoint error, given by its HTTP status and a
+// message naming the OAuth2 error, to a gRPC status. It returns nil if there
+// is no closer match than the error itself.
+func oauthError(status int, msg string) error {
+	switch {
+	case strings.Contains(msg, "invalid_scope"):
+		return grpc.Errorf(codes.PermissionDenied, "requested scopes were not granted")
+	case status == 401 || strings.Contains(msg, "invalid_client"):
+		return grpc.Errorf(codes.Unauthenticated, "invalid username or password")
+	case strings.Contains(msg, "invalid_grant") || strings.Contains(msg, "unauthorized_client"):
+		return grpc.Errorf(codes.PermissionDenied, "client may not use the client credentials grant")
+	case status >= 500:
+		return grpc.Errorf(codes.Unavailable, "token endpoint failed with status %d", status)
+	}
+	return nil
+}
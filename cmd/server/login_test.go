@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	register(t, s, &pb.RegisterRequest{Username: "alice", Password: "secret1", Scope: "read"})
+	register(t, s, &pb.RegisterRequest{Username: "web", Password: "secret1", GrantTypes: []string{"authorization_code"}})
+
+	tests := []struct {
+		name string
+		req  *pb.LoginRequest
+		code codes.Code
+	}{
+		{"valid", &pb.LoginRequest{Username: "alice", Password: "secret1"}, codes.OK},
+		{"granted scope", &pb.LoginRequest{Username: "alice", Password: "secret1", Scopes: []string{"read"}}, codes.OK},
+		{"empty password", &pb.LoginRequest{Username: "alice"}, codes.InvalidArgument},
+		{"wrong password", &pb.LoginRequest{Username: "alice", Password: "secret2"}, codes.Unauthenticated},
+		{"unknown username", &pb.LoginRequest{Username: "bob", Password: "secret1"}, codes.Unauthenticated},
+		{"scope not granted", &pb.LoginRequest{Username: "alice", Password: "secret1", Scopes: []string{"write"}}, codes.PermissionDenied},
+		{"no client credentials grant", &pb.LoginRequest{Username: "web", Password: "secret1"}, codes.PermissionDenied},
+	}
+
+	for _, test := range tests {
+		res, err := s.Login(context.Background(), test.req)
+		if got := code(err); got != test.code {
+			t.Errorf("%s: got %v, want %v (%v)", test.name, got, test.code, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if res.AccessToken == "" || res.ExpiresAt == 0 {
+			t.Errorf("%s: got %v, want a token that expires", test.name, res)
+		}
+		if len(res.Scopes) != len(test.req.Scopes) {
+			t.Errorf("%s: got scopes %v, want %v", test.name, res.Scopes, test.req.Scopes)
+		}
+	}
+}
+
+func TestTokenError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		code codes.Code
+	}{
+		{"canceled", canceled, errors.New("Post http://hydra/oauth2/token: context canceled"), codes.Canceled},
+		{"deadline exceeded", expired, errors.New("Post http://hydra/oauth2/token: context deadline exceeded"), codes.DeadlineExceeded},
+		{"unreachable", context.Background(), errors.New("Post http://hydra/oauth2/token: connection refused"), codes.Unavailable},
+		{"invalid client", context.Background(), errors.New(`oauth2: cannot fetch token: 401 Unauthorized\nResponse: {"error":"invalid_client"}`), codes.Unauthenticated},
+		{"invalid scope", context.Background(), errors.New(`oauth2: cannot fetch token: 400 Bad Request\nResponse: {"error":"invalid_scope"}`), codes.PermissionDenied},
+		{"server error", context.Background(), errors.New("oauth2: cannot fetch token: 503 Service Unavailable"), codes.Unavailable},
+	}
+
+	for _, test := range tests {
+		if got := grpc.Code(tokenError(test.ctx, test.err)); got != test.code {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.code)
+		}
+	}
+}
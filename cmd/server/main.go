@@ -1,72 +1,389 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"github.com/ory-am/hydra/client"
-	"github.com/ory-am/hydra/sdk"
+	"github.com/pborman/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	pb "github.com/tthanh/identity-demo/proto"
 )
 
-const (
-	port = ":50051"
-)
-
-var (
-	hydra *sdk.Client
-)
+type server struct {
+	// clientsMu serializes changes to hydra clients so that concurrent
+	// requests can not claim the same id or username, or interleave the
+	// steps of replacing a client.
+	clientsMu sync.Mutex
 
-type server struct{}
+	backend   backend
+	usernames usernameIndex
+	revoked   *revocationList
+}
 
 func (s *server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	id := "id"
+	if req.Username == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "username must not be empty")
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, grpc.Errorf(codes.InvalidArgument, "password must be at least %d characters long", minPasswordLength)
+	}
+
+	id := req.Id
+	if id == "" {
+		id = uuid.New()
+	} else if uuid.Parse(id) == nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "id %q is not a valid UUID", id)
+	}
+
+	if err := validateMetadata(req); err != nil {
+		return nil, err
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	exists, err := s.clientExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, grpc.Errorf(codes.AlreadyExists, "client %q already exists", id)
+	}
+
+	err = s.usernames.Reserve(req.Username, id)
+	if err == errUsernameTaken {
+		return nil, grpc.Errorf(codes.AlreadyExists, "username %q is already taken", req.Username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	grantTypes := req.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = append([]string(nil), defaultGrantTypes...)
+	}
 
 	newClient := &client.Client{
-		ID:           id,
-		Name:         req.Username,
-		Secret:       req.Password,
-		RedirectURIs: []string{"uri"},
+		ID:                id,
+		Name:              req.Username,
+		Secret:            req.Password,
+		RedirectURIs:      req.RedirectUris,
+		GrantTypes:        grantTypes,
+		ResponseTypes:     req.ResponseTypes,
+		Scope:             req.Scope,
+		Owner:             req.Owner,
+		PolicyURI:         req.PolicyUri,
+		TermsOfServiceURI: req.TosUri,
+		ClientURI:         req.ClientUri,
+		LogoURI:           req.LogoUri,
+		Contacts:          req.Contacts,
 	}
 
-	var err = hydra.Client.CreateClient(newClient)
+	err = s.backend.Clients(ctx).CreateClient(newClient)
+	if err != nil {
+		if rerr := s.usernames.Release(req.Username); rerr != nil {
+			log.Printf("failed to release username %q: %v", req.Username, rerr)
+		}
+	}
+	if hydraStatus(err) == http.StatusConflict {
+		return nil, grpc.Errorf(codes.AlreadyExists, "client %q already exists", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toRegisterResponse(newClient), nil
+}
+
+// clientExists reports whether hydra already knows a client with the given id.
+func (s *server) clientExists(ctx context.Context, id string) (bool, error) {
+	_, err := s.backend.Clients(ctx).GetConcreteClient(id)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// newUsernameIndex opens the configured username index and rebuilds it from
+// the clients stored in hydra.
+func newUsernameIndex(file string, storage client.Storage) (usernameIndex, error) {
+	var index usernameIndex = newMemoryIndex()
+	if file != "" {
+		f, err := newFileIndex(file)
+		if err != nil {
+			return nil, err
+		}
+		index = f
+	}
+
+	clients, err := storage.GetClients()
 	if err != nil {
 		return nil, err
 	}
 
-	res := &pb.RegisterResponse{
-		Id:       id,
-		Username: req.Username,
+	conflicts, err := rebuildIndex(index, clients)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range conflicts {
+		log.Printf("client %q shares username %q with another client, it is not indexed", id, clients[id].Name)
 	}
 
-	return res, nil
+	return index, nil
 }
 
 func main() {
-	var err error
-	hydra, err = sdk.Connect(
-		sdk.ClientID("tthanh"),
-		sdk.ClientSecret("secret"),
-		sdk.ClusterURL("https://localhost:4444"),
-		sdk.SkipTLSVerify(),
-	)
+	v := viper.New()
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Serves the Identity gRPC API in front of hydra",
+		Long: `Serves the Identity gRPC API in front of hydra.
+
+Every flag can also be set in the configuration file, using the nesting shown
+in the README, or through an environment variable named after the key, e.g.
+IDENTITY_HYDRA_CLIENT_SECRET for hydra.client_secret.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadConfig(cmd, v)
+			if err != nil {
+				return err
+			}
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return serve(c)
+		},
+	}
+	addFlags(cmd, v)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func serve(c *config) error {
+	logger := newLogger(c.Log.Format)
+
+	b, err := newBackend(c)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	lis, err := net.Listen("tcp", port)
+	usernames, err := newUsernameIndex(c.UsernameIndex, b.Clients(context.Background()))
 	if err != nil {
-		log.Fatalf("failed to listen on: %v", err)
+		return fmt.Errorf("failed to build username index: %v", err)
 	}
 
-	s := grpc.NewServer()
+	if c.MetricsListen != "" {
+		if err := serveMetrics(c.MetricsListen); err != nil {
+			return fmt.Errorf("failed to serve metrics: %v", err)
+		}
+	}
+
+	// Traces are kept in memory, so they are only collected when they can
+	// be looked at.
+	grpc.EnableTracing = c.AdminListen != ""
+	if c.AdminListen != "" {
+		if err := serveAdmin(c.AdminListen); err != nil {
+			return fmt.Errorf("failed to serve traces: %v", err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", c.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on: %v", err)
+	}
+
+	srv := &server{
+		backend:   b,
+		usernames: usernames,
+		revoked:   newRevocationList(),
+	}
+
+	unary := []grpc.UnaryServerInterceptor{loggingInterceptor(logger), metricsInterceptor, errorInterceptor, featureInterceptor(c)}
+	stream := []grpc.StreamServerInterceptor{loggingStreamInterceptor(logger), metricsStreamInterceptor, errorStreamInterceptor}
+	if c.Auth.Enabled {
+		auth := newAuthenticator(c.Auth.PublicMethods, b, srv.revoked)
+		unary = append(unary, auth.unary)
+		stream = append(stream, auth.stream)
+	} else {
+		log.Printf("authentication is disabled, every caller may use every method")
+	}
+
+	intercept := chainUnaryInterceptors(unary...)
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(intercept),
+		grpc.StreamInterceptor(chainStreamInterceptors(stream...)),
+	}
+	var certs *certReloader
+	if c.TLS.CertFile != "" {
+		certs, err = newCertReloader(c.TLS.CertFile, c.TLS.KeyFile, c.TLS.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		defer certs.Close()
+		opts = append(opts, grpc.Creds(certs))
+	}
+
+	s := grpc.NewServer(opts...)
+
+	pb.RegisterIdentityServer(s, srv)
+
+	h := newHealthServer()
+	healthpb.RegisterHealthServer(s, h)
+	stop := make(chan struct{})
+	defer close(stop)
+	go watchHydra(b, h, c.Health.Interval, stop)
+
+	if c.Reflection {
+		reflection.Register(s)
+	}
+
+	var gw *http.Server
+	if c.GatewayListen != "" {
+		gw, err = serveGateway(c.GatewayListen, &gateway{srv: srv, intercept: intercept}, certs)
+		if err != nil {
+			return fmt.Errorf("failed to serve gateway: %v", err)
+		}
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(lis) }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-served:
+		return err
+	case sig := <-signals:
+		log.Printf("received %v, shutting down", sig)
+	}
+	deadline := time.Now().Add(c.ShutdownTimeout)
+
+	h.Shutdown()
+	gatewayStopped := make(chan struct{})
+	go func() {
+		defer close(gatewayStopped)
+		if gw == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+		defer cancel()
+		if err := gw.Shutdown(ctx); err != nil {
+			gw.Close()
+		}
+	}()
+	gracefulStop(s, c.ShutdownTimeout)
+	<-gatewayStopped
 
-	pb.RegisterIdentityServer(s, &server{})
+	// Calls cut off by Stop may still be changing hydra clients; wait for
+	// them, so that no client is left half created, deleted or replaced. The
+	// request to hydra under way at the deadline may still take its time.
+	if !srv.waitForClientChanges(time.Until(deadline) + c.Hydra.RequestTimeout) {
+		log.Printf("hydra clients are still being changed, exiting anyway")
+		return nil
+	}
+	log.Printf("shut down")
+	return nil
+}
+
+// waitForClientChanges waits up to timeout for running changes to hydra
+// clients to finish, and keeps new ones from starting. It reports whether
+// they finished.
+func (s *server) waitForClientChanges(timeout time.Duration) bool {
+	locked := make(chan struct{})
+	go func() {
+		s.clientsMu.Lock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// newBackend connects to the configured hydra cluster, retrying for a while if
+// it can not be reached. In development mode it sets up an in-memory backend
+// instead, and with an embedded hydra it serves hydra's API over such a
+// backend and connects to that. Both generate an admin client whose
+// credentials are logged once.
+//
+// Calls to hydra, in process as well as over HTTP, are timed, traced, retried
+// and guarded by a circuit breaker.
+func newBackend(c *config) (backend, error) {
+	if !c.Dev && !c.EmbeddedHydra {
+		b, err := connectHydraWithRetry(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to hydra: %v", err)
+		}
+		return newResilientBackend(b, c), nil
+	}
+
+	m, err := newMemoryBackend()
+	if err != nil {
+		return nil, err
+	}
+	id, secret, err := m.Bootstrap()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("all data is kept in memory; admin credentials: %s / %s", id, secret)
+	if c.Dev {
+		return newResilientBackend(m, c), nil
+	}
+
+	endpoint, err := serveEmbeddedHydra(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start embedded hydra: %v", err)
+	}
+	log.Printf("embedded hydra listens on %s", endpoint)
+
+	embedded := *c
+	embedded.Hydra.ClusterURL = endpoint
+	embedded.Hydra.ClientID = id
+	embedded.Hydra.ClientSecret = secret
+	b, err := connectHydra(&embedded, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to embedded hydra: %v", err)
+	}
+	return newResilientBackend(b, c), nil
+}
 
-	s.Serve(lis)
+// gracefulStop waits for in-flight calls to finish, and stops s forcibly once
+// timeout has passed.
+func gracefulStop(s *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
 
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("calls still running after %v, stopping", timeout)
+		s.Stop()
+	}
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// newTestServer returns a server backed by a fresh memoryBackend.
+func newTestServer(t *testing.T) *server {
+	b, err := newMemoryBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &server{
+		backend:   b,
+		usernames: newMemoryIndex(),
+		revoked:   newRevocationList(),
+	}
+}
+
+// register registers a user and returns its ID.
+func register(t *testing.T, s *server, req *pb.RegisterRequest) string {
+	res, err := s.Register(context.Background(), req)
+	if err != nil {
+		t.Fatalf("registering %q: %v", req.Username, err)
+	}
+	return res.Id
+}
+
+// code returns the gRPC code a call failing with err ends with.
+func code(err error) codes.Code {
+	_, err = translateError(err)
+	return grpc.Code(err)
+}
+
+func TestRegister(t *testing.T) {
+	s := newTestServer(t)
+	taken := register(t, s, &pb.RegisterRequest{Username: "taken", Password: "secret1"})
+
+	tests := []struct {
+		name string
+		req  *pb.RegisterRequest
+		code codes.Code
+	}{
+		{"valid", &pb.RegisterRequest{Username: "alice", Password: "secret1"}, codes.OK},
+		{"with id", &pb.RegisterRequest{Username: "bob", Password: "secret1", Id: "7f6c1b0e-3c1a-4c55-9d4a-5b8f0f6f2a11"}, codes.OK},
+		{"empty username", &pb.RegisterRequest{Password: "secret1"}, codes.InvalidArgument},
+		{"empty password", &pb.RegisterRequest{Username: "carol"}, codes.InvalidArgument},
+		{"short password", &pb.RegisterRequest{Username: "carol", Password: "abc"}, codes.InvalidArgument},
+		{"invalid id", &pb.RegisterRequest{Username: "carol", Password: "secret1", Id: "carol"}, codes.InvalidArgument},
+		{"unknown grant type", &pb.RegisterRequest{Username: "carol", Password: "secret1", GrantTypes: []string{"device_code"}}, codes.InvalidArgument},
+		{"relative redirect uri", &pb.RegisterRequest{Username: "carol", Password: "secret1", RedirectUris: []string{"/callback"}}, codes.InvalidArgument},
+		{"taken username", &pb.RegisterRequest{Username: "taken", Password: "secret1"}, codes.AlreadyExists},
+		{"taken id", &pb.RegisterRequest{Username: "carol", Password: "secret1", Id: taken}, codes.AlreadyExists},
+	}
+
+	for _, test := range tests {
+		res, err := s.Register(context.Background(), test.req)
+		if got := code(err); got != test.code {
+			t.Errorf("%s: got %v, want %v (%v)", test.name, got, test.code, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if test.req.Id != "" && res.Id != test.req.Id {
+			t.Errorf("%s: got id %q, want %q", test.name, res.Id, test.req.Id)
+		}
+		if id, _ := s.usernames.Lookup(test.req.Username); id != res.Id {
+			t.Errorf("%s: username maps to %q, want %q", test.name, id, res.Id)
+		}
+		if !reflect.DeepEqual(res.GrantTypes, defaultGrantTypes) {
+			t.Errorf("%s: got grant types %v, want %v", test.name, res.GrantTypes, defaultGrantTypes)
+		}
+	}
+
+	if _, ok := s.usernames.Lookup("carol"); ok {
+		t.Error("failed registrations left carol in the username index")
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ory-am/fosite"
+	"github.com/ory-am/fosite/compose"
+	"github.com/ory-am/fosite/hash"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+
+	"github.com/ory-am/hydra/client"
+	"github.com/ory-am/hydra/connection"
+	"github.com/ory-am/hydra/firewall"
+	"github.com/ory-am/hydra/jwk"
+	hoauth2 "github.com/ory-am/hydra/oauth2"
+	"github.com/ory-am/hydra/warden"
+	"github.com/ory-am/ladon"
+)
+
+const (
+	// memoryIssuer is the issuer of the tokens of a memoryBackend.
+	memoryIssuer = "identity-dev"
+
+	// memoryTokenLifespan is how long tokens of a memoryBackend are valid.
+	memoryTokenLifespan = time.Hour
+)
+
+// memoryBackend keeps clients, policies and tokens in process, using hydra's
+// memory managers and a fosite provider that issues tokens the way hydra's
+// token endpoint does. Everything is lost when the process exits.
+type memoryBackend struct {
+	clients      *client.MemoryManager
+	connections  *connection.MemoryManager
+	keys         *jwk.MemoryManager
+	policies     *ladon.MemoryManager
+	oauth2       fosite.OAuth2Provider
+	warden       *warden.LocalWarden
+	introspector *hoauth2.LocalIntrospector
+}
+
+var _ backend = (*memoryBackend)(nil)
+
+func newMemoryBackend() (*memoryBackend, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	b := &memoryBackend{
+		clients: &client.MemoryManager{
+			Clients: map[string]client.Client{},
+			Hasher:  &hash.BCrypt{WorkFactor: 10},
+		},
+		connections: connection.NewMemoryManager(),
+		keys:        &jwk.MemoryManager{},
+		policies:    ladon.NewMemoryManager(),
+	}
+
+	conf := &compose.Config{AccessTokenLifespan: memoryTokenLifespan}
+	b.oauth2 = compose.Compose(
+		conf,
+		newTokenStore(b.clients),
+		compose.NewOAuth2HMACStrategy(conf, secret),
+		compose.OAuth2ClientCredentialsGrantFactory,
+	)
+	b.warden = &warden.LocalWarden{
+		Warden:              &ladon.Ladon{Manager: b.policies},
+		OAuth2:              b.oauth2,
+		AccessTokenLifespan: memoryTokenLifespan,
+		Issuer:              memoryIssuer,
+	}
+	b.introspector = &hoauth2.LocalIntrospector{
+		OAuth2:              b.oauth2,
+		AccessTokenLifespan: memoryTokenLifespan,
+		Issuer:              memoryIssuer,
+	}
+
+	return b, nil
+}
+
+func (b *memoryBackend) Clients(context.Context) client.Storage         { return b.clients }
+func (b *memoryBackend) Connections(context.Context) connection.Manager { return b.connections }
+func (b *memoryBackend) Keys(context.Context) jwk.Manager               { return b.keys }
+func (b *memoryBackend) Policies(context.Context) ladon.Manager         { return b.policies }
+func (b *memoryBackend) Warden(context.Context) firewall.Firewall       { return memoryWarden{b.warden} }
+
+func (b *memoryBackend) Introspector(_ context.Context, caller string) hoauth2.Introspector {
+	return memoryIntrospector{b.introspector, b.warden, caller}
+}
+
+// Ping succeeds unless ctx is done, since the managers are in process.
+func (b *memoryBackend) Ping(ctx context.Context) error { return ctx.Err() }
+
+// Token does what hydra's token endpoint does for the client credentials
+// grant, without the round trip through HTTP.
+func (b *memoryBackend) Token(ctx context.Context, id, secret string, scopes []string) (*oauth2.Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	r, err := http.NewRequest("POST", "/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth(id, secret)
+
+	session := hoauth2.NewSession(id)
+	req, err := b.oauth2.NewAccessRequest(ctx, r, session)
+	if err != nil {
+		return nil, fositeError(err)
+	}
+	for _, scope := range req.GetRequestedScopes() {
+		if fosite.HierarchicScopeStrategy(req.GetClient().GetScopes(), scope) {
+			req.GrantScope(scope)
+		}
+	}
+
+	resp, err := b.oauth2.NewAccessResponse(ctx, r, req)
+	if err != nil {
+		return nil, fositeError(err)
+	}
+
+	token := &oauth2.Token{
+		AccessToken: resp.GetAccessToken(),
+		TokenType:   resp.GetTokenType(),
+		Expiry:      time.Now().Add(memoryTokenLifespan),
+	}
+	return token.WithExtra(map[string]interface{}{
+		"scope": strings.Join(req.GetGrantedScopes(), " "),
+	}), nil
+}
+
+// Bootstrap creates a client that may do anything to the Identity service's
+// and to hydra's resources, and returns its credentials, since nothing could
+// be called with authentication enabled otherwise.
+func (b *memoryBackend) Bootstrap() (id, secret string, err error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	id, secret = "admin", hex.EncodeToString(raw)
+
+	if err := b.clients.CreateClient(&client.Client{
+		ID:         id,
+		Name:       id,
+		Secret:     secret,
+		GrantTypes: []string{"client_credentials"},
+		Scope:      "hydra",
+	}); err != nil {
+		return "", "", err
+	}
+
+	if err := b.policies.Create(&ladon.DefaultPolicy{
+		ID:          "admin",
+		Description: "Allows the generated admin client everything.",
+		Subjects:    []string{id},
+		Resources:   []string{"rn:identity:<.*>", "rn:hydra:<.*>"},
+		Actions:     []string{"<.*>"},
+		Effect:      ladon.AllowAccess,
+	}); err != nil {
+		return "", "", err
+	}
+
+	return id, secret, nil
+}
+
+// fositeError maps an error of the fosite provider to a gRPC status, like
+// tokenError does for hydra's token endpoint.
+func fositeError(err error) error {
+	rfc := fosite.ErrorToRFC6749Error(err)
+	if e := oauthError(rfc.StatusCode, rfc.Name); e != nil {
+		return e
+	}
+	log.Printf("issuing token failed: %v", err)
+	return err
+}
+
+// memoryWarden fails like warden.HTTPWarden does.
+type memoryWarden struct {
+	*warden.LocalWarden
+}
+
+func (w memoryWarden) IsAllowed(ctx context.Context, req *ladon.Request) error {
+	if err := w.LocalWarden.IsAllowed(ctx, req); err != nil {
+		return errors.New(errForbidden)
+	}
+	return nil
+}
+
+func (w memoryWarden) TokenAllowed(ctx context.Context, token string, req *ladon.Request, scopes ...string) (*firewall.Context, error) {
+	c, err := w.LocalWarden.TokenAllowed(ctx, token, req, scopes...)
+	if err != nil {
+		return nil, errors.New(errTokenInvalid)
+	}
+	return c, nil
+}
+
+func (w memoryWarden) TokenValid(ctx context.Context, token string, scopes ...string) (*firewall.Context, error) {
+	c, err := w.LocalWarden.TokenValid(ctx, token, scopes...)
+	if err != nil {
+		return nil, errors.New(errTokenInvalid)
+	}
+	return c, nil
+}
+
+// memoryIntrospector fails like the callerIntrospector of an sdkBackend
+// does, including hydra's check that the caller is the token's audience.
+type memoryIntrospector struct {
+	*hoauth2.LocalIntrospector
+	warden *warden.LocalWarden
+	caller string
+}
+
+func (i memoryIntrospector) IntrospectToken(ctx context.Context, token string) (*hoauth2.Introspection, error) {
+	c, err := i.warden.TokenValid(ctx, i.caller)
+	if err != nil {
+		return nil, errors.New(errTokenInactive)
+	}
+	res, err := i.LocalIntrospector.IntrospectToken(ctx, token)
+	if err != nil || res.Audience != c.Subject {
+		return nil, errors.New(errTokenInactive)
+	}
+	return res, nil
+}
+
+// tokenStore is the fosite storage of a memoryBackend. Clients come from the
+// backend's client manager; sessions are kept per token signature.
+type tokenStore struct {
+	client.Manager
+
+	mu       sync.RWMutex
+	sessions map[string]fosite.Requester
+}
+
+func newTokenStore(clients client.Manager) *tokenStore {
+	return &tokenStore{Manager: clients, sessions: map[string]fosite.Requester{}}
+}
+
+func (s *tokenStore) create(kind, signature string, req fosite.Requester) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[kind+":"+signature] = req
+	return nil
+}
+
+func (s *tokenStore) get(kind, signature string) (fosite.Requester, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	req, ok := s.sessions[kind+":"+signature]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	return req, nil
+}
+
+func (s *tokenStore) delete(kind, signature string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, kind+":"+signature)
+	return nil
+}
+
+func (s *tokenStore) CreateAuthorizeCodeSession(_ context.Context, code string, req fosite.Requester) error {
+	return s.create("code", code, req)
+}
+
+func (s *tokenStore) GetAuthorizeCodeSession(_ context.Context, code string, _ interface{}) (fosite.Requester, error) {
+	return s.get("code", code)
+}
+
+func (s *tokenStore) DeleteAuthorizeCodeSession(_ context.Context, code string) error {
+	return s.delete("code", code)
+}
+
+func (s *tokenStore) CreateAccessTokenSession(_ context.Context, signature string, req fosite.Requester) error {
+	return s.create("access", signature, req)
+}
+
+func (s *tokenStore) GetAccessTokenSession(_ context.Context, signature string, _ interface{}) (fosite.Requester, error) {
+	return s.get("access", signature)
+}
+
+func (s *tokenStore) DeleteAccessTokenSession(_ context.Context, signature string) error {
+	return s.delete("access", signature)
+}
+
+func (s *tokenStore) CreateRefreshTokenSession(_ context.Context, signature string, req fosite.Requester) error {
+	return s.create("refresh", signature, req)
+}
+
+func (s *tokenStore) GetRefreshTokenSession(_ context.Context, signature string, _ interface{}) (fosite.Requester, error) {
+	return s.get("refresh", signature)
+}
+
+func (s *tokenStore) DeleteRefreshTokenSession(_ context.Context, signature string) error {
+	return s.delete("refresh", signature)
+}
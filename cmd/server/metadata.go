@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory-am/fosite"
+	"github.com/ory-am/hydra/client"
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+var (
+	// knownGrantTypes are the grant types hydra's fosite handlers implement.
+	knownGrantTypes = fosite.Arguments{"authorization_code", "implicit", "refresh_token", "client_credentials", "password"}
+
+	// knownResponseTypes are the response types hydra's fosite handlers
+	// implement.
+	knownResponseTypes = fosite.Arguments{"code", "token", "id_token"}
+
+	// defaultGrantTypes are given to clients registered without grant types:
+	// the client credentials grant, which Login and ChangePassword use, and
+	// hydra's own default.
+	defaultGrantTypes = []string{"client_credentials", "authorization_code"}
+)
+
+// validateMetadata checks the optional client metadata of a registration.
+func validateMetadata(req *pb.RegisterRequest) error {
+	for _, uri := range req.RedirectUris {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() {
+			return grpc.Errorf(codes.InvalidArgument, "redirect uri %q is not an absolute URI", uri)
+		}
+		if u.Fragment != "" {
+			return grpc.Errorf(codes.InvalidArgument, "redirect uri %q must not contain a fragment", uri)
+		}
+	}
+
+	for name, uri := range map[string]string{
+		"policy_uri": req.PolicyUri,
+		"tos_uri":    req.TosUri,
+		"client_uri": req.ClientUri,
+		"logo_uri":   req.LogoUri,
+	} {
+		if uri == "" {
+			continue
+		}
+		if u, err := url.Parse(uri); err != nil || !u.IsAbs() {
+			return grpc.Errorf(codes.InvalidArgument, "%s %q is not an absolute URI", name, uri)
+		}
+	}
+
+	for _, t := range req.GrantTypes {
+		if !knownGrantTypes.Has(t) {
+			return grpc.Errorf(codes.InvalidArgument, "unknown grant type %q", t)
+		}
+	}
+
+	for _, t := range req.ResponseTypes {
+		if !knownResponseTypes.Has(t) {
+			return grpc.Errorf(codes.InvalidArgument, "unknown response type %q", t)
+		}
+	}
+
+	for _, c := range req.Contacts {
+		if c == "" {
+			return grpc.Errorf(codes.InvalidArgument, "contacts must not be empty")
+		}
+	}
+
+	return nil
+}
+
+func toRegisterResponse(c *client.Client) *pb.RegisterResponse {
+	return &pb.RegisterResponse{
+		Id:            c.ID,
+		Username:      c.Name,
+		RedirectUris:  c.RedirectURIs,
+		GrantTypes:    c.GetGrantTypes(),
+		ResponseTypes: c.GetResponseTypes(),
+		Scope:         c.Scope,
+		Owner:         c.Owner,
+		PolicyUri:     c.PolicyURI,
+		TosUri:        c.TermsOfServiceURI,
+		ClientUri:     c.ClientURI,
+		LogoUri:       c.LogoURI,
+		Contacts:      c.Contacts,
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+)
+
+// metrics holds every metric the server exports.
+var metrics = &registry{}
+
+var (
+	// rpcCalls counts finished calls.
+	rpcCalls = metrics.counter(
+		"identity_rpc_calls_total",
+		"Finished gRPC calls.",
+		"method", "code",
+	)
+
+	// rpcDuration is how long calls took.
+	rpcDuration = metrics.histogram(
+		"identity_rpc_duration_seconds",
+		"Time taken by gRPC calls.",
+		"method", "code",
+	)
+
+	// hydraDuration is how long each call to hydra took, retries counted
+	// separately.
+	hydraDuration = metrics.histogram(
+		"identity_hydra_call_duration_seconds",
+		"Time taken by calls to hydra.",
+		"manager", "operation",
+	)
+
+	// hydraBreakerState is the state of the circuit breaker for calls to
+	// hydra.
+	hydraBreakerState = metrics.gauge(
+		"identity_hydra_breaker_state",
+		"State of the circuit breaker for calls to hydra: 0 closed, 1 open, 2 half open.",
+	)
+
+	// hydraTokenExpiry is when the token the server authenticates to hydra
+	// with expires.
+	hydraTokenExpiry = metrics.gauge(
+		"identity_hydra_token_expiry_timestamp_seconds",
+		"Unix time at which the server's current hydra token expires.",
+	)
+
+	// hydraTokenFetches counts the tokens the server fetched to authenticate
+	// to hydra with, by why they were fetched.
+	hydraTokenFetches = metrics.counter(
+		"identity_hydra_token_fetches_total",
+		"Tokens fetched by the server to authenticate to hydra with.",
+		"reason", "result",
+	)
+)
+
+// labelEscaper escapes label values for the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// durationBuckets are the upper bounds, in seconds, of the buckets of every
+// histogram.
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// registry writes metrics in the Prometheus text format.
+type registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// metric is a family of samples sharing a name.
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+func (r *registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+func (r *registry) gauge(name, help string) *gauge {
+	g := &gauge{family: family{name: name, help: help}}
+	r.register(g)
+	return g
+}
+
+func (r *registry) counter(name, help string, labels ...string) *counter {
+	c := &counter{family: family{name: name, help: help, labels: labels}, values: map[string]float64{}}
+	r.register(c)
+	return c
+}
+
+func (r *registry) histogram(name, help string, labels ...string) *histogram {
+	h := &histogram{family: family{name: name, help: help, labels: labels}, series: map[string]*series{}}
+	r.register(h)
+	return h
+}
+
+// gauge is a single value that goes up and down.
+type gauge struct {
+	family
+
+	mu    sync.Mutex
+	value float64
+}
+
+func (g *gauge) Set(v float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = v
+}
+
+func (g *gauge) name() string { return g.family.name }
+
+func (g *gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.header(w, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.family.name, formatFloat(g.value))
+}
+
+// family describes a metric whose samples are told apart by labels.
+type family struct {
+	name, help string
+	labels     []string
+}
+
+// key joins label values into a map key.
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("%s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders the labels for the values joined in key, plus extra,
+// which is already rendered.
+func (f *family) labelPairs(key, extra string) string {
+	var pairs []string
+	if len(f.labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, f.labels[i], labelEscaper.Replace(v)))
+		}
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (f *family) header(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, kind)
+}
+
+// counter is a value per label combination that only goes up.
+type counter struct {
+	family
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (c *counter) Inc(values ...string) {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key]++
+}
+
+func (c *counter) name() string { return c.family.name }
+
+func (c *counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.family.name, c.labelPairs(key, ""), formatFloat(c.values[key]))
+	}
+}
+
+// histogram counts observed durations per label combination in
+// durationBuckets.
+type histogram struct {
+	family
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// Observe records a duration for the given label values.
+func (h *histogram) Observe(d time.Duration, values ...string) {
+	key := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(durationBuckets))}
+		h.series[key] = s
+	}
+	seconds := d.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.count++
+	s.sum += seconds
+}
+
+func (h *histogram) name() string { return h.family.name }
+
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+		for i, bound := range durationBuckets {
+			le := fmt.Sprintf("le=%q", formatFloat(bound))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.family.name, h.labelPairs(key, le), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.family.name, h.labelPairs(key, `le="+Inf"`), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.family.name, h.labelPairs(key, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.family.name, h.labelPairs(key, ""), s.count)
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// serveMetrics serves the metrics at /metrics on addr until the listener
+// fails.
+func serveMetrics(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	go func() {
+		if err := http.Serve(lis, mux); err != nil {
+			log.Printf("metrics listener stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// metricsInterceptor counts unary calls and records how long they took.
+func metricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// metricsStreamInterceptor is the streaming counterpart of
+// metricsInterceptor.
+func metricsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeCall(info.FullMethod, start, err)
+	return err
+}
+
+func observeCall(method string, start time.Time, err error) {
+	code := grpc.Code(err).String()
+	rpcCalls.Inc(method, code)
+	rpcDuration.Observe(time.Since(start), method, code)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// minPasswordLength matches the minimum client secret length hydra accepts.
+const minPasswordLength = 6
+
+func (s *server) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	if req.Id == "" || req.OldPassword == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "id and old_password must not be empty")
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		return nil, grpc.Errorf(codes.InvalidArgument, "new_password must be at least %d characters long", minPasswordLength)
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	old, err := s.getClient(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// hydra never hands out secrets, so the only way to check the old
+	// password is to use it, in the client credentials grant. Clients
+	// registered with other grant types can not change their password.
+	if !old.GetGrantTypes().Has("client_credentials") {
+		return nil, grpc.Errorf(codes.FailedPrecondition,
+			"client %q may not use the client credentials grant, which checking the old password needs", req.Id)
+	}
+	if _, err := s.backend.Token(ctx, req.Id, req.OldPassword, nil); err != nil {
+		return nil, err
+	}
+
+	// hydra can not update clients, so the client is replaced. If creating
+	// the replacement fails the old client is restored with its old secret.
+	if err := s.backend.Clients(ctx).DeleteClient(req.Id); err != nil {
+		return nil, err
+	}
+
+	replacement := *old
+	replacement.Secret = req.NewPassword
+	if err := s.backend.Clients(ctx).CreateClient(&replacement); err != nil {
+		restored := *old
+		restored.Secret = req.OldPassword
+		if rerr := s.backend.Clients(ctx).CreateClient(&restored); rerr != nil {
+			log.Printf("client %q was deleted but could not be restored: %v", req.Id, rerr)
+			return nil, grpc.Errorf(codes.DataLoss, "client %q was lost while changing its password", req.Id)
+		}
+		return nil, err
+	}
+
+	if req.RevokeTokens {
+		s.revoked.Revoke(req.Id, time.Now())
+	}
+
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+// revocationList remembers, per client, the time before which every token
+// issued to that client is considered revoked. hydra has no revocation
+// endpoint, so this is only enforced by the Identity service's own token
+// RPCs.
+type revocationList struct {
+	sync.RWMutex
+	before map[string]time.Time
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{before: map[string]time.Time{}}
+}
+
+// Revoke revokes the tokens issued to client id before the given time.
+// Introspection only reports whole seconds, so the cutoff is rounded up to
+// the next one: tokens issued in the same second as the revocation count as
+// revoked, whether they were issued before it or not.
+func (r *revocationList) Revoke(id string, before time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	cutoff := before.Truncate(time.Second)
+	if cutoff.Before(before) {
+		cutoff = cutoff.Add(time.Second)
+	}
+	r.before[id] = cutoff
+}
+
+// Revoked reports whether a token issued to client id at issuedAt has been
+// revoked.
+func (r *revocationList) Revoked(id string, issuedAt time.Time) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	before, ok := r.before[id]
+	return ok && issuedAt.Before(before)
+}
+
+// Cutoff returns the time from which tokens issued to client id are no longer
+// revoked, or the zero time if none were.
+func (r *revocationList) Cutoff(id string) time.Time {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.before[id]
+}
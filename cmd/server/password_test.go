@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+func TestChangePassword(t *testing.T) {
+	s := newTestServer(t)
+	alice := register(t, s, &pb.RegisterRequest{Username: "alice", Password: "secret1"})
+	web := register(t, s, &pb.RegisterRequest{Username: "web", Password: "secret1", GrantTypes: []string{"authorization_code"}})
+
+	tests := []struct {
+		name string
+		req  *pb.ChangePasswordRequest
+		code codes.Code
+	}{
+		{"empty old password", &pb.ChangePasswordRequest{Id: alice, NewPassword: "secret2"}, codes.InvalidArgument},
+		{"short new password", &pb.ChangePasswordRequest{Id: alice, OldPassword: "secret1", NewPassword: "abc"}, codes.InvalidArgument},
+		{"unknown id", &pb.ChangePasswordRequest{Id: "nobody", OldPassword: "secret1", NewPassword: "secret2"}, codes.NotFound},
+		{"wrong old password", &pb.ChangePasswordRequest{Id: alice, OldPassword: "secret9", NewPassword: "secret2"}, codes.Unauthenticated},
+		{"no client credentials grant", &pb.ChangePasswordRequest{Id: web, OldPassword: "secret1", NewPassword: "secret2"}, codes.FailedPrecondition},
+		{"valid", &pb.ChangePasswordRequest{Id: alice, OldPassword: "secret1", NewPassword: "secret2"}, codes.OK},
+		{"old password after the change", &pb.ChangePasswordRequest{Id: alice, OldPassword: "secret1", NewPassword: "secret3"}, codes.Unauthenticated},
+	}
+
+	for _, test := range tests {
+		_, err := s.ChangePassword(context.Background(), test.req)
+		if got := code(err); got != test.code {
+			t.Errorf("%s: got %v, want %v (%v)", test.name, got, test.code, err)
+		}
+	}
+
+	for password, want := range map[string]codes.Code{"secret1": codes.Unauthenticated, "secret2": codes.OK} {
+		_, err := s.Login(context.Background(), &pb.LoginRequest{Username: "alice", Password: password})
+		if got := code(err); got != want {
+			t.Errorf("login with %s: got %v, want %v (%v)", password, got, want, err)
+		}
+	}
+}
+
+func TestChangePasswordRevokeTokens(t *testing.T) {
+	s := newTestServer(t)
+	alice := register(t, s, &pb.RegisterRequest{Username: "alice", Password: "secret1"})
+	login, err := s.Login(context.Background(), &pb.LoginRequest{Username: "alice", Password: "secret1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	introspection, err := s.IntrospectToken(context.Background(), &pb.IntrospectTokenRequest{Token: login.AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !introspection.Active {
+		t.Fatal("IntrospectToken: token is not active before the change")
+	}
+
+	_, err = s.ChangePassword(context.Background(), &pb.ChangePasswordRequest{
+		Id: alice, OldPassword: "secret1", NewPassword: "secret2", RevokeTokens: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := s.ValidateToken(context.Background(), &pb.ValidateTokenRequest{Token: login.AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid.Valid {
+		t.Error("ValidateToken: token issued before the change is valid")
+	}
+
+	introspection, err = s.IntrospectToken(context.Background(), &pb.IntrospectTokenRequest{Token: login.AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if introspection.Active {
+		t.Error("IntrospectToken: token issued before the change is active")
+	}
+
+	login, err = s.Login(context.Background(), &pb.LoginRequest{Username: "alice", Password: "secret2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err = s.ValidateToken(context.Background(), &pb.ValidateTokenRequest{Token: login.AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid.Valid {
+		t.Error("ValidateToken: token of a login right after the change is not valid")
+	}
+	introspection, err = s.IntrospectToken(context.Background(), &pb.IntrospectTokenRequest{Token: login.AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !introspection.Active {
+		t.Error("IntrospectToken: token of a login right after the change is not active")
+	}
+}
+
+func TestRevocationList(t *testing.T) {
+	before := time.Date(2017, 1, 1, 12, 0, 0, 700e6, time.UTC)
+
+	tests := []struct {
+		name     string
+		id       string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{"other client", "bob", before.Add(-time.Hour), false},
+		{"earlier second", "alice", before.Add(-time.Second), true},
+		{"same second, earlier", "alice", before.Add(-500 * time.Millisecond), true},
+		{"same second, whole seconds", "alice", before.Truncate(time.Second), true},
+		{"same second, later", "alice", before.Add(200 * time.Millisecond), true},
+		{"next second", "alice", before.Add(300 * time.Millisecond), false},
+	}
+
+	r := newRevocationList()
+	r.Revoke("alice", before)
+	for _, test := range tests {
+		if got := r.Revoked(test.id, test.issuedAt); got != test.revoked {
+			t.Errorf("%s: got revoked %v, want %v", test.name, got, test.revoked)
+		}
+	}
+
+	for revoked, want := range map[time.Time]time.Time{
+		before:                       before.Truncate(time.Second).Add(time.Second),
+		before.Truncate(time.Second): before.Truncate(time.Second),
+	} {
+		r.Revoke("alice", revoked)
+		if got := r.Cutoff("alice"); !got.Equal(want) {
+			t.Errorf("revoking at %v: got cutoff %v, want %v", revoked, got, want)
+		}
+	}
+	if got := r.Cutoff("bob"); !got.IsZero() {
+		t.Errorf("got cutoff %v for a client without revocation, want none", got)
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenk/backoff"
+	"github.com/ory-am/fosite"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory-am/hydra/client"
+	"github.com/ory-am/hydra/firewall"
+	hoauth2 "github.com/ory-am/hydra/oauth2"
+	"github.com/ory-am/ladon"
+)
+
+// retryInitialInterval is the first pause before a call to hydra is retried.
+const retryInitialInterval = 100 * time.Millisecond
+
+// transient reports whether err may go away if the call is repeated, because
+// hydra could not be reached or failed with a server error. The end of the
+// caller's context is not, though context.DeadlineExceeded is a net.Error.
+func transient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if grpc.Code(err) == codes.Unavailable {
+		return true
+	}
+
+	for e := err; e != nil; e = unwrap(e) {
+		if e == context.Canceled || e == context.DeadlineExceeded {
+			return false
+		}
+	}
+	for e := err; e != nil; e = unwrap(e) {
+		// url.Error is a net.Error too, but may wrap permanent errors such
+		// as an untrusted certificate.
+		if _, ok := e.(*url.Error); ok {
+			continue
+		}
+		if _, ok := e.(net.Error); ok {
+			return true
+		}
+	}
+
+	// hydra answers many requests it rejects, such as a client with a short
+	// secret, with status 500.
+	if upstreamError(err) != "" {
+		return false
+	}
+	if hydraStatus(err) >= 500 {
+		return true
+	}
+	if m := tokenStatusPattern.FindStringSubmatch(err.Error()); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		return status >= 500
+	}
+	return false
+}
+
+// connectHydraWithRetry calls connectHydra until it succeeds, fails with an
+// error that is not transient, or c.Hydra.ConnectTimeout has passed.
+func connectHydraWithRetry(c *config) (*sdkBackend, error) {
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxElapsedTime = c.Hydra.ConnectTimeout
+
+	var deadline time.Time
+	if c.Hydra.ConnectTimeout > 0 {
+		deadline = time.Now().Add(c.Hydra.ConnectTimeout)
+	}
+
+	var b *sdkBackend
+	var permanent error
+	err := backoff.RetryNotify(func() error {
+		var err error
+		b, err = connectHydra(c, deadline)
+		if err != nil && !transient(err) {
+			permanent = err
+			return nil
+		}
+		return err
+	}, bo, func(err error, wait time.Duration) {
+		log.Printf("could not connect to hydra, retrying in %v: %v", wait, err)
+	})
+	if permanent != nil {
+		return nil, permanent
+	}
+	return b, err
+}
+
+// States of a breaker, as exported by hydraBreakerState.
+const (
+	breakerClosed   = 0
+	breakerOpen     = 1
+	breakerHalfOpen = 2
+)
+
+// breaker is a circuit breaker for calls to hydra. Once threshold calls in a
+// row failed with transient errors it opens, and calls fail right away with
+// Unavailable. After cooldown a single trial call is let through, which
+// closes the breaker if hydra answers and opens it again if not.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if wait := b.cooldown - time.Since(b.openedAt); wait > 0 || b.trial {
+		return grpc.Errorf(codes.Unavailable, "hydra is unavailable, not calling it for now")
+	}
+	b.trial = true
+	hydraBreakerState.Set(breakerHalfOpen)
+	return nil
+}
+
+// record takes note of the outcome of a call that allow let through.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !transient(err) {
+		if !b.openedAt.IsZero() {
+			log.Printf("hydra answers again, closing circuit breaker")
+		}
+		b.failures = 0
+		b.openedAt = time.Time{}
+		hydraBreakerState.Set(breakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold || !b.openedAt.IsZero() {
+		if b.openedAt.IsZero() {
+			log.Printf("%d calls to hydra failed in a row, opening circuit breaker: %v", b.failures, err)
+		}
+		b.openedAt = time.Now()
+		hydraBreakerState.Set(breakerOpen)
+	}
+}
+
+// resilience runs calls to hydra through a breaker, retries idempotent ones
+// on transient errors and records how long every attempt took.
+type resilience struct {
+	breaker      *breaker
+	retryTimeout time.Duration
+}
+
+func (r *resilience) do(ctx context.Context, manager, operation string, idempotent bool, op func() error) error {
+	if err := r.breaker.allow(); err != nil {
+		return err
+	}
+
+	var err error
+	attempt := func() error {
+		start := time.Now()
+		err = traceHydraCall(ctx, manager, operation, op)
+		hydraDuration.Observe(time.Since(start), manager, operation)
+		return err
+	}
+	if idempotent {
+		bo := backoff.NewExponentialBackOff()
+		bo.InitialInterval = retryInitialInterval
+		bo.MaxElapsedTime = r.retryTimeout
+		backoff.Retry(func() error {
+			if transient(attempt()) {
+				return err
+			}
+			return nil
+		}, contextBackOff{bo, ctx})
+	} else {
+		attempt()
+	}
+
+	r.breaker.record(err)
+	return err
+}
+
+// contextBackOff stops retrying once ctx is done, or would be before the
+// next attempt.
+type contextBackOff struct {
+	backoff.BackOff
+	ctx context.Context
+}
+
+func (b contextBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if b.ctx.Err() != nil {
+		return backoff.Stop
+	}
+	if deadline, ok := b.ctx.Deadline(); ok && time.Now().Add(next).After(deadline) {
+		return backoff.Stop
+	}
+	return next
+}
+
+// resilientBackend wraps the calls the Identity service makes to a backend
+// in a resilience. Connections, keys and policies are passed through, since
+// the service does not use them, and so are pings, which only time and trace
+// themselves so that health reflects hydra rather than the breaker.
+type resilientBackend struct {
+	backend
+	r *resilience
+}
+
+func newResilientBackend(b backend, c *config) *resilientBackend {
+	return &resilientBackend{
+		backend: b,
+		r: &resilience{
+			breaker: &breaker{
+				threshold: c.Hydra.BreakerThreshold,
+				cooldown:  c.Hydra.BreakerCooldown,
+			},
+			retryTimeout: c.Hydra.RetryTimeout,
+		},
+	}
+}
+
+func (b *resilientBackend) Clients(ctx context.Context) client.Storage {
+	return resilientClients{b.backend.Clients(ctx), b.r, ctx}
+}
+
+func (b *resilientBackend) Warden(ctx context.Context) firewall.Firewall {
+	return resilientWarden{b.backend.Warden(ctx), b.r}
+}
+
+func (b *resilientBackend) Introspector(ctx context.Context, caller string) hoauth2.Introspector {
+	return resilientIntrospector{b.backend.Introspector(ctx, caller), b.r}
+}
+
+func (b *resilientBackend) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := traceHydraCall(ctx, "health", "ping", func() error {
+		return b.backend.Ping(ctx)
+	})
+	hydraDuration.Observe(time.Since(start), "health", "ping")
+	return err
+}
+
+func (b *resilientBackend) Token(ctx context.Context, id, secret string, scopes []string) (token *oauth2.Token, err error) {
+	err = b.r.do(ctx, "oauth2", "token", true, func() error {
+		token, err = b.backend.Token(ctx, id, secret, scopes)
+		return err
+	})
+	return token, err
+}
+
+type resilientClients struct {
+	client.Storage
+	r   *resilience
+	ctx context.Context
+}
+
+func (s resilientClients) GetClient(id string) (c fosite.Client, err error) {
+	err = s.r.do(s.ctx, "clients", "get", true, func() error {
+		c, err = s.Storage.GetClient(id)
+		return err
+	})
+	return c, err
+}
+
+func (s resilientClients) GetConcreteClient(id string) (c *client.Client, err error) {
+	err = s.r.do(s.ctx, "clients", "get", true, func() error {
+		c, err = s.Storage.GetConcreteClient(id)
+		return err
+	})
+	return c, err
+}
+
+func (s resilientClients) GetClients() (clients map[string]client.Client, err error) {
+	err = s.r.do(s.ctx, "clients", "list", true, func() error {
+		clients, err = s.Storage.GetClients()
+		return err
+	})
+	return clients, err
+}
+
+func (s resilientClients) CreateClient(c *client.Client) error {
+	return s.r.do(s.ctx, "clients", "create", false, func() error {
+		return s.Storage.CreateClient(c)
+	})
+}
+
+func (s resilientClients) DeleteClient(id string) error {
+	return s.r.do(s.ctx, "clients", "delete", true, func() error {
+		return s.Storage.DeleteClient(id)
+	})
+}
+
+type resilientWarden struct {
+	firewall.Firewall
+	r *resilience
+}
+
+func (w resilientWarden) IsAllowed(ctx context.Context, req *ladon.Request) error {
+	return w.r.do(ctx, "warden", "allowed", true, func() error {
+		return w.Firewall.IsAllowed(ctx, req)
+	})
+}
+
+func (w resilientWarden) TokenAllowed(ctx context.Context, token string, req *ladon.Request, scopes ...string) (c *firewall.Context, err error) {
+	err = w.r.do(ctx, "warden", "token_allowed", true, func() error {
+		c, err = w.Firewall.TokenAllowed(ctx, token, req, scopes...)
+		return err
+	})
+	return c, err
+}
+
+func (w resilientWarden) TokenValid(ctx context.Context, token string, scopes ...string) (c *firewall.Context, err error) {
+	err = w.r.do(ctx, "warden", "token_valid", true, func() error {
+		c, err = w.Firewall.TokenValid(ctx, token, scopes...)
+		return err
+	})
+	return c, err
+}
+
+type resilientIntrospector struct {
+	hoauth2.Introspector
+	r *resilience
+}
+
+func (i resilientIntrospector) IntrospectToken(ctx context.Context, token string) (res *hoauth2.Introspection, err error) {
+	err = i.r.do(ctx, "introspector", "introspect", true, func() error {
+		res, err = i.Introspector.IntrospectToken(ctx, token)
+		return err
+	})
+	return res, err
+}
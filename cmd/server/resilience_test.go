@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	unavailable := grpc.Errorf(codes.Unavailable, "hydra is down")
+	notFound := grpc.Errorf(codes.NotFound, "not found")
+
+	b := &breaker{threshold: 2, cooldown: cooldown}
+	steps := []struct {
+		name   string
+		wait   time.Duration
+		record error
+		allow  bool
+		state  float64
+	}{
+		{name: "first failure", record: unavailable, allow: true, state: breakerClosed},
+		{name: "success resets the count", record: nil, allow: true, state: breakerClosed},
+		{name: "failure after success", record: unavailable, allow: true, state: breakerClosed},
+		{name: "permanent error resets the count", record: notFound, allow: true, state: breakerClosed},
+		{name: "failure after permanent error", record: unavailable, allow: true, state: breakerClosed},
+		{name: "threshold reached", record: unavailable, allow: false, state: breakerOpen},
+		{name: "trial fails", wait: cooldown, record: unavailable, allow: false, state: breakerOpen},
+		{name: "trial succeeds", wait: cooldown, record: nil, allow: true, state: breakerClosed},
+	}
+
+	for _, step := range steps {
+		time.Sleep(step.wait)
+		if err := b.allow(); err != nil {
+			t.Fatalf("%s: call not allowed: %v", step.name, err)
+		}
+		if step.wait > 0 {
+			if hydraBreakerState.value != breakerHalfOpen {
+				t.Errorf("%s: state during the trial is %v, want half open", step.name, hydraBreakerState.value)
+			}
+			if err := b.allow(); grpc.Code(err) != codes.Unavailable {
+				t.Errorf("%s: second call during the trial got %v, want Unavailable", step.name, err)
+			}
+		}
+
+		b.record(step.record)
+		err := b.allow()
+		if (err == nil) != step.allow {
+			t.Errorf("%s: next call allowed is %v, want %v", step.name, err == nil, step.allow)
+		}
+		if err != nil && grpc.Code(err) != codes.Unavailable {
+			t.Errorf("%s: got %v, want Unavailable", step.name, err)
+		}
+		if hydraBreakerState.value != step.state {
+			t.Errorf("%s: state is %v, want %v", step.name, hydraBreakerState.value, step.state)
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"unavailable status", grpc.Errorf(codes.Unavailable, "down"), true},
+		{"not found status", grpc.Errorf(codes.NotFound, "not found"), false},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"url error around a network error", &url.Error{Op: "Get", URL: "http://hydra", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"url error around a permanent error", &url.Error{Op: "Get", URL: "https://hydra", Err: errors.New("x509: certificate signed by unknown authority")}, false},
+		{"hydra server error", errors.New("Expected status code 200, got 502.\nBad Gateway"), true},
+		{"hydra rejection", errors.New("Expected 2xx status code but got 500.\n{\"error\":\"The client secret must be at least 6 characters long\",\"code\":500}"), false},
+		{"hydra client error", errors.New("Expected status code 200, got 404.\n"), false},
+		{"token endpoint server error", errors.New("oauth2: cannot fetch token: 503 Service Unavailable"), true},
+		{"token endpoint client error", errors.New("oauth2: cannot fetch token: 401 Unauthorized"), false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"url error around an exceeded deadline", &url.Error{Op: "Get", URL: "http://hydra", Err: context.DeadlineExceeded}, false},
+		{"canceled", context.Canceled, false},
+	}
+
+	for _, test := range tests {
+		if got := transient(test.err); got != test.transient {
+			t.Errorf("%s: got transient %v, want %v", test.name, got, test.transient)
+		}
+	}
+}
+
+func TestResilienceStopsWithContext(t *testing.T) {
+	r := &resilience{
+		breaker:      &breaker{threshold: 100, cooldown: time.Minute},
+		retryTimeout: time.Minute,
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiring, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		attempts int
+	}{
+		{"canceled", canceled, context.Canceled, 1},
+		{"hydra down until the deadline", expiring, grpc.Errorf(codes.Unavailable, "hydra is down"), 4},
+	}
+
+	for _, test := range tests {
+		attempts := 0
+		start := time.Now()
+		r.do(test.ctx, "clients", "get", true, func() error {
+			attempts++
+			return test.err
+		})
+		if attempts > test.attempts {
+			t.Errorf("%s: got %d attempts, want at most %d", test.name, attempts, test.attempts)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: retried for %v after the context ended", test.name, elapsed)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+	"net"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// certReloader serves the server certificate and, for mutual TLS, the pool
+// of client CAs, and reloads both whenever their files change on disk. A
+// broken update is logged and the previous material is kept.
+type certReloader struct {
+	certFile, keyFile, clientCAFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+
+	watcher *fsnotify.Watcher
+}
+
+func newCertReloader(certFile, keyFile, clientCAFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the directories rather than the files, so that files replaced
+	// by a rename, as done by most deployment tools, are still noticed.
+	dirs := map[string]bool{}
+	for _, f := range []string{certFile, keyFile, clientCAFile} {
+		if f != "" {
+			dirs[filepath.Dir(f)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	r.watcher = w
+	go r.watch()
+	return r, nil
+}
+
+func (r *certReloader) watch() {
+	for {
+		select {
+		case e, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if !r.watches(e.Name) || e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				log.Printf("keeping previous TLS certificates, reload failed: %v", err)
+				continue
+			}
+			log.Printf("reloaded TLS certificates after change to %s", e.Name)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("watching TLS certificates failed: %v", err)
+		}
+	}
+}
+
+func (r *certReloader) watches(name string) bool {
+	name = filepath.Clean(name)
+	for _, f := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if f != "" && filepath.Clean(f) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		pool, err = loadCertPool(r.clientCAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = pool
+	return nil
+}
+
+// Close stops watching the certificate files.
+func (r *certReloader) Close() error {
+	return r.watcher.Close()
+}
+
+// config returns a server configuration holding the most recently loaded
+// certificates.
+func (r *certReloader) config() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{*r.cert},
+		NextProtos:   []string{"h2"},
+	}
+	if r.clientCA != nil {
+		c.ClientCAs = r.clientCA
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c
+}
+
+// certReloader is its own credentials.TransportCredentials, since the
+// credentials returned by credentials.NewTLS copy the tls.Config they are
+// given and would keep serving the certificates loaded at startup.
+var _ credentials.TransportCredentials = (*certReloader)(nil)
+
+func (r *certReloader) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn := tls.Server(rawConn, r.config())
+	if err := conn.Handshake(); err != nil {
+		return nil, nil, err
+	}
+	return conn, credentials.TLSInfo{State: conn.ConnectionState()}, nil
+}
+
+func (r *certReloader) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("certReloader only supports server handshakes")
+}
+
+func (r *certReloader) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{
+		SecurityProtocol: "tls",
+		SecurityVersion:  "1.2",
+	}
+}
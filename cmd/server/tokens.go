@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+// These are the errors hydra's HTTP introspector and warden return for a
+// request that succeeded but carried an unusable token.
+const (
+	errTokenInactive = "Token is malformed, expired or otherwise invalid"
+	errTokenInvalid  = "Token is not valid"
+)
+
+func (s *server) IntrospectToken(ctx context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectTokenResponse, error) {
+	if req.Token == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "token must not be empty")
+	}
+
+	// hydra only reports a token as active to its audience, so the token
+	// introspects itself.
+	i, err := s.backend.Introspector(ctx, req.Token).IntrospectToken(ctx, req.Token)
+	if err != nil && err.Error() == errTokenInactive {
+		return &pb.IntrospectTokenResponse{Active: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := i.ClientID
+	if clientID == "" {
+		clientID = i.Audience
+	}
+	if s.revoked.Revoked(clientID, time.Unix(i.IssuedAt, 0)) {
+		return &pb.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &pb.IntrospectTokenResponse{
+		Active:   i.Active,
+		Scope:    i.Scope,
+		ClientId: i.ClientID,
+		Sub:      i.Subject,
+		Exp:      i.ExpiresAt,
+		Iat:      i.IssuedAt,
+		Nbf:      i.NotBefore,
+		Username: i.Username,
+		Aud:      i.Audience,
+		Iss:      i.Issuer,
+		Ext:      stringMap(i.Extra),
+	}, nil
+}
+
+func (s *server) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	if req.Token == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "token must not be empty")
+	}
+
+	c, err := s.backend.Warden(ctx).TokenValid(ctx, req.Token, req.Scopes...)
+	if err != nil && err.Error() == errTokenInvalid {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.revoked.Revoked(c.Audience, c.IssuedAt) {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+
+	res := &pb.ValidateTokenResponse{
+		Valid:         true,
+		Subject:       c.Subject,
+		GrantedScopes: c.GrantedScopes,
+		Issuer:        c.Issuer,
+		Audience:      c.Audience,
+		Extra:         stringMap(c.Extra),
+	}
+	if !c.IssuedAt.IsZero() {
+		res.IssuedAt = c.IssuedAt.Unix()
+	}
+	if !c.ExpiresAt.IsZero() {
+		res.ExpiresAt = c.ExpiresAt.Unix()
+	}
+
+	return res, nil
+}
+
+// stringMap flattens arbitrary session data into a string map, JSON encoding
+// every value that is not already a string.
+func stringMap(m map[string]interface{}) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if s, ok := v.(string); ok {
+			out[k] = s
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		out[k] = string(data)
+	}
+	return out
+}
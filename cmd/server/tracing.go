@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/trace"
+
+	"google.golang.org/grpc"
+)
+
+// serveAdmin serves grpc's request traces and event logs at /debug/requests
+// and /debug/events on addr. Callers are let in as trace.AuthRequest decides,
+// which by default admits only loopback addresses. Message payloads are never
+// shown, since requests contain passwords.
+func serveAdmin(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/requests", func(w http.ResponseWriter, req *http.Request) {
+		if allowTraces(w, req) {
+			trace.Render(w, req, false)
+		}
+	})
+	mux.HandleFunc("/debug/events", func(w http.ResponseWriter, req *http.Request) {
+		if allowTraces(w, req) {
+			trace.RenderEvents(w, req, false)
+		}
+	})
+	go http.Serve(lis, mux)
+	return nil
+}
+
+func allowTraces(w http.ResponseWriter, req *http.Request) bool {
+	if any, _ := trace.AuthRequest(req); !any {
+		http.Error(w, "not allowed", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// traceHydraCall runs op, a call to hydra on behalf of the call in ctx, in a
+// trace of its own and notes it in the call's trace, if there is one. It only
+// runs op when tracing is off.
+func traceHydraCall(ctx context.Context, manager, operation string, op func() error) error {
+	if !grpc.EnableTracing {
+		return op()
+	}
+
+	tr := trace.New("hydra."+manager, operation)
+	defer tr.Finish()
+	if id := requestID(ctx); id != "" {
+		tr.LazyPrintf("request id %s", id)
+	}
+
+	err := op()
+	if err != nil {
+		tr.LazyPrintf("%v", err)
+		tr.SetError()
+	}
+
+	if parent, ok := trace.FromContext(ctx); ok {
+		if err != nil {
+			parent.LazyPrintf("hydra %s %s failed: %v", manager, operation, err)
+		} else {
+			parent.LazyPrintf("hydra %s %s", manager, operation)
+		}
+	}
+	return err
+}
+
+// requestIDTransport sends a request ID with every request to hydra, so that
+// hydra's logs can be matched with the server's.
+type requestIDTransport struct {
+	id   string
+	base http.RoundTripper
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set("X-Request-ID", t.id)
+	return t.base.RoundTrip(r)
+}
+
+// withRequestID returns a client like c that sends the request ID of the call
+// in ctx, or c itself if there is none.
+func withRequestID(ctx context.Context, c *http.Client) *http.Client {
+	id := requestID(ctx)
+	if id == "" {
+		return c
+	}
+
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	withID := *c
+	withID.Transport = &requestIDTransport{id: id, base: base}
+	return &withID
+}
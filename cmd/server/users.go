@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/base64"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory-am/hydra/client"
+	pb "github.com/tthanh/identity-demo/proto"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+func (s *server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	id := req.Id
+	if id == "" {
+		if req.Username == "" {
+			return nil, grpc.Errorf(codes.InvalidArgument, "either id or username must be set")
+		}
+
+		var ok bool
+		id, ok = s.usernames.Lookup(req.Username)
+		if !ok {
+			return nil, grpc.Errorf(codes.NotFound, "user %q not found", req.Username)
+		}
+	}
+
+	c, err := s.getClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUser(c), nil
+}
+
+func (s *server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if req.PageSize < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "page_size must not be negative")
+	}
+	size := int(req.PageSize)
+	if size == 0 {
+		size = defaultPageSize
+	} else if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid page_token")
+	}
+
+	clients, err := s.backend.Clients(ctx).GetClients()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(clients))
+	for id, c := range clients {
+		if after != "" && id <= after {
+			continue
+		}
+		if !strings.HasPrefix(c.Name, req.UsernamePrefix) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	res := &pb.ListUsersResponse{}
+	if len(ids) > size {
+		ids = ids[:size]
+		res.NextPageToken = encodePageToken(ids[size-1])
+	}
+
+	for _, id := range ids {
+		c := clients[id]
+		res.Users = append(res.Users, toUser(&c))
+	}
+
+	return res, nil
+}
+
+func (s *server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if req.Id == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "id must not be empty")
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	c, err := s.getClient(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.backend.Clients(ctx).DeleteClient(c.ID); err != nil {
+		return nil, err
+	}
+
+	if owner, ok := s.usernames.Lookup(c.Name); ok && owner == c.ID {
+		if err := s.usernames.Release(c.Name); err != nil {
+			log.Printf("failed to release username %q: %v", c.Name, err)
+		}
+	}
+
+	return &pb.DeleteUserResponse{}, nil
+}
+
+// getClient fetches a client from hydra, translating a missing client into
+// a NotFound status.
+func (s *server) getClient(ctx context.Context, id string) (*client.Client, error) {
+	c, err := s.backend.Clients(ctx).GetConcreteClient(id)
+	if isNotFound(err) {
+		return nil, grpc.Errorf(codes.NotFound, "user %q not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func toUser(c *client.Client) *pb.User {
+	return &pb.User{
+		Id:       c.ID,
+		Username: c.Name,
+	}
+}
+
+// Page tokens are the opaque encoding of the last client ID on a page.
+func encodePageToken(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodePageToken(token string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(token)
+	return string(id), err
+}
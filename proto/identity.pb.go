@@ -11,6 +11,25 @@ It is generated from these files:
 It has these top-level messages:
 	RegisterRequest
 	RegisterResponse
+	User
+	GetUserRequest
+	ListUsersRequest
+	ListUsersResponse
+	DeleteUserRequest
+	DeleteUserResponse
+	LoginRequest
+	LoginResponse
+	IntrospectTokenRequest
+	IntrospectTokenResponse
+	ValidateTokenRequest
+	ValidateTokenResponse
+	AccessRequest
+	IsAllowedResponse
+	TokenAllowedRequest
+	TokenAllowedResponse
+	ChangePasswordRequest
+	ChangePasswordResponse
+	ErrorDetail
 */
 package identity
 
@@ -34,9 +53,27 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// RegisterRequest creates a user backed by an OAuth2 client. Apart from
+// username and password every field is optional and follows the OpenID
+// Connect dynamic client registration metadata.
 type RegisterRequest struct {
 	Username string `protobuf:"bytes,1,opt,name=username" json:"username,omitempty"`
 	Password string `protobuf:"bytes,2,opt,name=password" json:"password,omitempty"`
+	// id is optional. When empty the server generates a random UUID.
+	Id           string   `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+	RedirectUris []string `protobuf:"bytes,4,rep,name=redirect_uris,json=redirectUris" json:"redirect_uris,omitempty"`
+	// grant_types defaults to client_credentials, which Login and
+	// ChangePassword need, and authorization_code.
+	GrantTypes    []string `protobuf:"bytes,5,rep,name=grant_types,json=grantTypes" json:"grant_types,omitempty"`
+	ResponseTypes []string `protobuf:"bytes,6,rep,name=response_types,json=responseTypes" json:"response_types,omitempty"`
+	// scope is a space separated list of scopes the client may request.
+	Scope     string   `protobuf:"bytes,7,opt,name=scope" json:"scope,omitempty"`
+	Owner     string   `protobuf:"bytes,8,opt,name=owner" json:"owner,omitempty"`
+	PolicyUri string   `protobuf:"bytes,9,opt,name=policy_uri,json=policyUri" json:"policy_uri,omitempty"`
+	TosUri    string   `protobuf:"bytes,10,opt,name=tos_uri,json=tosUri" json:"tos_uri,omitempty"`
+	ClientUri string   `protobuf:"bytes,11,opt,name=client_uri,json=clientUri" json:"client_uri,omitempty"`
+	LogoUri   string   `protobuf:"bytes,12,opt,name=logo_uri,json=logoUri" json:"logo_uri,omitempty"`
+	Contacts  []string `protobuf:"bytes,13,rep,name=contacts" json:"contacts,omitempty"`
 }
 
 func (m *RegisterRequest) Reset()                    { *m = RegisterRequest{} }
@@ -44,9 +81,21 @@ func (m *RegisterRequest) String() string            { return proto.CompactTextS
 func (*RegisterRequest) ProtoMessage()               {}
 func (*RegisterRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+// RegisterResponse holds the effective metadata of the new client, including
+// defaults filled in for omitted grant and response types.
 type RegisterResponse struct {
-	Id       string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Username string `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+	Id            string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Username      string   `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+	RedirectUris  []string `protobuf:"bytes,3,rep,name=redirect_uris,json=redirectUris" json:"redirect_uris,omitempty"`
+	GrantTypes    []string `protobuf:"bytes,4,rep,name=grant_types,json=grantTypes" json:"grant_types,omitempty"`
+	ResponseTypes []string `protobuf:"bytes,5,rep,name=response_types,json=responseTypes" json:"response_types,omitempty"`
+	Scope         string   `protobuf:"bytes,6,opt,name=scope" json:"scope,omitempty"`
+	Owner         string   `protobuf:"bytes,7,opt,name=owner" json:"owner,omitempty"`
+	PolicyUri     string   `protobuf:"bytes,8,opt,name=policy_uri,json=policyUri" json:"policy_uri,omitempty"`
+	TosUri        string   `protobuf:"bytes,9,opt,name=tos_uri,json=tosUri" json:"tos_uri,omitempty"`
+	ClientUri     string   `protobuf:"bytes,10,opt,name=client_uri,json=clientUri" json:"client_uri,omitempty"`
+	LogoUri       string   `protobuf:"bytes,11,opt,name=logo_uri,json=logoUri" json:"logo_uri,omitempty"`
+	Contacts      []string `protobuf:"bytes,12,rep,name=contacts" json:"contacts,omitempty"`
 }
 
 func (m *RegisterResponse) Reset()                    { *m = RegisterResponse{} }
@@ -54,9 +103,302 @@ func (m *RegisterResponse) String() string            { return proto.CompactText
 func (*RegisterResponse) ProtoMessage()               {}
 func (*RegisterResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+type User struct {
+	Id       string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Username string `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+}
+
+func (m *User) Reset()                    { *m = User{} }
+func (m *User) String() string            { return proto.CompactTextString(m) }
+func (*User) ProtoMessage()               {}
+func (*User) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// GetUserRequest looks a user up by id or, if id is empty, by username.
+type GetUserRequest struct {
+	Id       string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Username string `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+}
+
+func (m *GetUserRequest) Reset()                    { *m = GetUserRequest{} }
+func (m *GetUserRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetUserRequest) ProtoMessage()               {}
+func (*GetUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type ListUsersRequest struct {
+	PageSize       int32  `protobuf:"varint,1,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	PageToken      string `protobuf:"bytes,2,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	UsernamePrefix string `protobuf:"bytes,3,opt,name=username_prefix,json=usernamePrefix" json:"username_prefix,omitempty"`
+}
+
+func (m *ListUsersRequest) Reset()                    { *m = ListUsersRequest{} }
+func (m *ListUsersRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListUsersRequest) ProtoMessage()               {}
+func (*ListUsersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+type ListUsersResponse struct {
+	Users []*User `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	// next_page_token is empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+}
+
+func (m *ListUsersResponse) Reset()                    { *m = ListUsersResponse{} }
+func (m *ListUsersResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListUsersResponse) ProtoMessage()               {}
+func (*ListUsersResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ListUsersResponse) GetUsers() []*User {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+type DeleteUserRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *DeleteUserRequest) Reset()                    { *m = DeleteUserRequest{} }
+func (m *DeleteUserRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteUserRequest) ProtoMessage()               {}
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+type DeleteUserResponse struct {
+}
+
+func (m *DeleteUserResponse) Reset()                    { *m = DeleteUserResponse{} }
+func (m *DeleteUserResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteUserResponse) ProtoMessage()               {}
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+type LoginRequest struct {
+	Username string   `protobuf:"bytes,1,opt,name=username" json:"username,omitempty"`
+	Password string   `protobuf:"bytes,2,opt,name=password" json:"password,omitempty"`
+	Scopes   []string `protobuf:"bytes,3,rep,name=scopes" json:"scopes,omitempty"`
+}
+
+func (m *LoginRequest) Reset()                    { *m = LoginRequest{} }
+func (m *LoginRequest) String() string            { return proto.CompactTextString(m) }
+func (*LoginRequest) ProtoMessage()               {}
+func (*LoginRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+type LoginResponse struct {
+	AccessToken string `protobuf:"bytes,1,opt,name=access_token,json=accessToken" json:"access_token,omitempty"`
+	TokenType   string `protobuf:"bytes,2,opt,name=token_type,json=tokenType" json:"token_type,omitempty"`
+	// expires_at is the expiry of access_token in seconds since the epoch.
+	ExpiresAt int64    `protobuf:"varint,3,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Scopes    []string `protobuf:"bytes,4,rep,name=scopes" json:"scopes,omitempty"`
+}
+
+func (m *LoginResponse) Reset()                    { *m = LoginResponse{} }
+func (m *LoginResponse) String() string            { return proto.CompactTextString(m) }
+func (*LoginResponse) ProtoMessage()               {}
+func (*LoginResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+type IntrospectTokenRequest struct {
+	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+}
+
+func (m *IntrospectTokenRequest) Reset()                    { *m = IntrospectTokenRequest{} }
+func (m *IntrospectTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*IntrospectTokenRequest) ProtoMessage()               {}
+func (*IntrospectTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+// IntrospectTokenResponse mirrors the token introspection response of
+// RFC 7662. Timestamps are in seconds since the epoch and non-string ext
+// values are JSON encoded.
+type IntrospectTokenResponse struct {
+	Active   bool              `protobuf:"varint,1,opt,name=active" json:"active,omitempty"`
+	Scope    string            `protobuf:"bytes,2,opt,name=scope" json:"scope,omitempty"`
+	ClientId string            `protobuf:"bytes,3,opt,name=client_id,json=clientId" json:"client_id,omitempty"`
+	Sub      string            `protobuf:"bytes,4,opt,name=sub" json:"sub,omitempty"`
+	Exp      int64             `protobuf:"varint,5,opt,name=exp" json:"exp,omitempty"`
+	Iat      int64             `protobuf:"varint,6,opt,name=iat" json:"iat,omitempty"`
+	Nbf      int64             `protobuf:"varint,7,opt,name=nbf" json:"nbf,omitempty"`
+	Username int64             `protobuf:"varint,8,opt,name=username" json:"username,omitempty"`
+	Aud      string            `protobuf:"bytes,9,opt,name=aud" json:"aud,omitempty"`
+	Iss      string            `protobuf:"bytes,10,opt,name=iss" json:"iss,omitempty"`
+	Ext      map[string]string `protobuf:"bytes,11,rep,name=ext" json:"ext,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *IntrospectTokenResponse) Reset()                    { *m = IntrospectTokenResponse{} }
+func (m *IntrospectTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*IntrospectTokenResponse) ProtoMessage()               {}
+func (*IntrospectTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *IntrospectTokenResponse) GetExt() map[string]string {
+	if m != nil {
+		return m.Ext
+	}
+	return nil
+}
+
+type ValidateTokenRequest struct {
+	Token  string   `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Scopes []string `protobuf:"bytes,2,rep,name=scopes" json:"scopes,omitempty"`
+}
+
+func (m *ValidateTokenRequest) Reset()                    { *m = ValidateTokenRequest{} }
+func (m *ValidateTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*ValidateTokenRequest) ProtoMessage()               {}
+func (*ValidateTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+// ValidateTokenResponse carries the token's session when valid is true.
+// Timestamps are in seconds since the epoch and non-string extra values are
+// JSON encoded.
+type ValidateTokenResponse struct {
+	Valid         bool              `protobuf:"varint,1,opt,name=valid" json:"valid,omitempty"`
+	Subject       string            `protobuf:"bytes,2,opt,name=subject" json:"subject,omitempty"`
+	GrantedScopes []string          `protobuf:"bytes,3,rep,name=granted_scopes,json=grantedScopes" json:"granted_scopes,omitempty"`
+	Issuer        string            `protobuf:"bytes,4,opt,name=issuer" json:"issuer,omitempty"`
+	Audience      string            `protobuf:"bytes,5,opt,name=audience" json:"audience,omitempty"`
+	IssuedAt      int64             `protobuf:"varint,6,opt,name=issued_at,json=issuedAt" json:"issued_at,omitempty"`
+	ExpiresAt     int64             `protobuf:"varint,7,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Extra         map[string]string `protobuf:"bytes,8,rep,name=extra" json:"extra,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ValidateTokenResponse) Reset()                    { *m = ValidateTokenResponse{} }
+func (m *ValidateTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*ValidateTokenResponse) ProtoMessage()               {}
+func (*ValidateTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ValidateTokenResponse) GetExtra() map[string]string {
+	if m != nil {
+		return m.Extra
+	}
+	return nil
+}
+
+// AccessRequest asks whether subject may perform action on resource.
+type AccessRequest struct {
+	Resource string            `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	Action   string            `protobuf:"bytes,2,opt,name=action" json:"action,omitempty"`
+	Subject  string            `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+	Context  map[string]string `protobuf:"bytes,4,rep,name=context" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *AccessRequest) Reset()                    { *m = AccessRequest{} }
+func (m *AccessRequest) String() string            { return proto.CompactTextString(m) }
+func (*AccessRequest) ProtoMessage()               {}
+func (*AccessRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *AccessRequest) GetContext() map[string]string {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+type IsAllowedResponse struct {
+	Allowed bool `protobuf:"varint,1,opt,name=allowed" json:"allowed,omitempty"`
+}
+
+func (m *IsAllowedResponse) Reset()                    { *m = IsAllowedResponse{} }
+func (m *IsAllowedResponse) String() string            { return proto.CompactTextString(m) }
+func (*IsAllowedResponse) ProtoMessage()               {}
+func (*IsAllowedResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+// TokenAllowedRequest asks whether the owner of token may perform the
+// request. The subject of request is ignored.
+type TokenAllowedRequest struct {
+	Token   string         `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Request *AccessRequest `protobuf:"bytes,2,opt,name=request" json:"request,omitempty"`
+	Scopes  []string       `protobuf:"bytes,3,rep,name=scopes" json:"scopes,omitempty"`
+}
+
+func (m *TokenAllowedRequest) Reset()                    { *m = TokenAllowedRequest{} }
+func (m *TokenAllowedRequest) String() string            { return proto.CompactTextString(m) }
+func (*TokenAllowedRequest) ProtoMessage()               {}
+func (*TokenAllowedRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *TokenAllowedRequest) GetRequest() *AccessRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+type TokenAllowedResponse struct {
+	Subject       string            `protobuf:"bytes,1,opt,name=subject" json:"subject,omitempty"`
+	GrantedScopes []string          `protobuf:"bytes,2,rep,name=granted_scopes,json=grantedScopes" json:"granted_scopes,omitempty"`
+	Issuer        string            `protobuf:"bytes,3,opt,name=issuer" json:"issuer,omitempty"`
+	Audience      string            `protobuf:"bytes,4,opt,name=audience" json:"audience,omitempty"`
+	IssuedAt      int64             `protobuf:"varint,5,opt,name=issued_at,json=issuedAt" json:"issued_at,omitempty"`
+	ExpiresAt     int64             `protobuf:"varint,6,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Extra         map[string]string `protobuf:"bytes,7,rep,name=extra" json:"extra,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *TokenAllowedResponse) Reset()                    { *m = TokenAllowedResponse{} }
+func (m *TokenAllowedResponse) String() string            { return proto.CompactTextString(m) }
+func (*TokenAllowedResponse) ProtoMessage()               {}
+func (*TokenAllowedResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *TokenAllowedResponse) GetExtra() map[string]string {
+	if m != nil {
+		return m.Extra
+	}
+	return nil
+}
+
+type ChangePasswordRequest struct {
+	Id          string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	OldPassword string `protobuf:"bytes,2,opt,name=old_password,json=oldPassword" json:"old_password,omitempty"`
+	NewPassword string `protobuf:"bytes,3,opt,name=new_password,json=newPassword" json:"new_password,omitempty"`
+	// revoke_tokens makes the Identity service treat every token issued to
+	// the user before the change as inactive.
+	RevokeTokens bool `protobuf:"varint,4,opt,name=revoke_tokens,json=revokeTokens" json:"revoke_tokens,omitempty"`
+}
+
+func (m *ChangePasswordRequest) Reset()                    { *m = ChangePasswordRequest{} }
+func (m *ChangePasswordRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChangePasswordRequest) ProtoMessage()               {}
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+type ChangePasswordResponse struct {
+}
+
+func (m *ChangePasswordResponse) Reset()                    { *m = ChangePasswordResponse{} }
+func (m *ChangePasswordResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChangePasswordResponse) ProtoMessage()               {}
+func (*ChangePasswordResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+// ErrorDetail is attached to failed calls as the binary trailer
+// identity-error-detail-bin.
+type ErrorDetail struct {
+	// reason is a stable, machine readable identifier such as
+	// HYDRA_NOT_FOUND or HYDRA_UNAVAILABLE.
+	Reason string `protobuf:"bytes,1,opt,name=reason" json:"reason,omitempty"`
+	// upstream_status is the HTTP status hydra answered with, if any.
+	UpstreamStatus  int32  `protobuf:"varint,2,opt,name=upstream_status,json=upstreamStatus" json:"upstream_status,omitempty"`
+	UpstreamMessage string `protobuf:"bytes,3,opt,name=upstream_message,json=upstreamMessage" json:"upstream_message,omitempty"`
+}
+
+func (m *ErrorDetail) Reset()                    { *m = ErrorDetail{} }
+func (m *ErrorDetail) String() string            { return proto.CompactTextString(m) }
+func (*ErrorDetail) ProtoMessage()               {}
+func (*ErrorDetail) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
 func init() {
 	proto.RegisterType((*RegisterRequest)(nil), "identity.RegisterRequest")
 	proto.RegisterType((*RegisterResponse)(nil), "identity.RegisterResponse")
+	proto.RegisterType((*User)(nil), "identity.User")
+	proto.RegisterType((*GetUserRequest)(nil), "identity.GetUserRequest")
+	proto.RegisterType((*ListUsersRequest)(nil), "identity.ListUsersRequest")
+	proto.RegisterType((*ListUsersResponse)(nil), "identity.ListUsersResponse")
+	proto.RegisterType((*DeleteUserRequest)(nil), "identity.DeleteUserRequest")
+	proto.RegisterType((*DeleteUserResponse)(nil), "identity.DeleteUserResponse")
+	proto.RegisterType((*LoginRequest)(nil), "identity.LoginRequest")
+	proto.RegisterType((*LoginResponse)(nil), "identity.LoginResponse")
+	proto.RegisterType((*IntrospectTokenRequest)(nil), "identity.IntrospectTokenRequest")
+	proto.RegisterType((*IntrospectTokenResponse)(nil), "identity.IntrospectTokenResponse")
+	proto.RegisterType((*ValidateTokenRequest)(nil), "identity.ValidateTokenRequest")
+	proto.RegisterType((*ValidateTokenResponse)(nil), "identity.ValidateTokenResponse")
+	proto.RegisterType((*AccessRequest)(nil), "identity.AccessRequest")
+	proto.RegisterType((*IsAllowedResponse)(nil), "identity.IsAllowedResponse")
+	proto.RegisterType((*TokenAllowedRequest)(nil), "identity.TokenAllowedRequest")
+	proto.RegisterType((*TokenAllowedResponse)(nil), "identity.TokenAllowedResponse")
+	proto.RegisterType((*ChangePasswordRequest)(nil), "identity.ChangePasswordRequest")
+	proto.RegisterType((*ChangePasswordResponse)(nil), "identity.ChangePasswordResponse")
+	proto.RegisterType((*ErrorDetail)(nil), "identity.ErrorDetail")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -71,6 +413,15 @@ const _ = grpc.SupportPackageIsVersion3
 
 type IdentityClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error)
+	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
+	IsAllowed(ctx context.Context, in *AccessRequest, opts ...grpc.CallOption) (*IsAllowedResponse, error)
+	TokenAllowed(ctx context.Context, in *TokenAllowedRequest, opts ...grpc.CallOption) (*TokenAllowedResponse, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error)
 }
 
 type identityClient struct {
@@ -90,10 +441,100 @@ func (c *identityClient) Register(ctx context.Context, in *RegisterRequest, opts
 	return out, nil
 }
 
+func (c *identityClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := grpc.Invoke(ctx, "/identity.Identity/GetUser", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	out := new(ListUsersResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/ListUsers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
+	out := new(DeleteUserResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/DeleteUser", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/Login", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error) {
+	out := new(IntrospectTokenResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/IntrospectToken", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error) {
+	out := new(ValidateTokenResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/ValidateToken", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) IsAllowed(ctx context.Context, in *AccessRequest, opts ...grpc.CallOption) (*IsAllowedResponse, error) {
+	out := new(IsAllowedResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/IsAllowed", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) TokenAllowed(ctx context.Context, in *TokenAllowedRequest, opts ...grpc.CallOption) (*TokenAllowedResponse, error) {
+	out := new(TokenAllowedResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/TokenAllowed", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *identityClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error) {
+	out := new(ChangePasswordResponse)
+	err := grpc.Invoke(ctx, "/identity.Identity/ChangePassword", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Identity service
 
 type IdentityServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error)
+	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
+	IsAllowed(context.Context, *AccessRequest) (*IsAllowedResponse, error)
+	TokenAllowed(context.Context, *TokenAllowedRequest) (*TokenAllowedResponse, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error)
 }
 
 func RegisterIdentityServer(s *grpc.Server, srv IdentityServer) {
@@ -118,6 +559,168 @@ func _Identity_Register_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Identity_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/GetUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/ListUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/DeleteUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/IntrospectToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_ValidateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).ValidateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/ValidateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).ValidateToken(ctx, req.(*ValidateTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_IsAllowed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).IsAllowed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/IsAllowed",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).IsAllowed(ctx, req.(*AccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_TokenAllowed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenAllowedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).TokenAllowed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/TokenAllowed",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).TokenAllowed(ctx, req.(*TokenAllowedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Identity_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdentityServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/identity.Identity/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdentityServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Identity_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "identity.Identity",
 	HandlerType: (*IdentityServer)(nil),
@@ -126,6 +729,42 @@ var _Identity_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Register",
 			Handler:    _Identity_Register_Handler,
 		},
+		{
+			MethodName: "GetUser",
+			Handler:    _Identity_GetUser_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _Identity_ListUsers_Handler,
+		},
+		{
+			MethodName: "DeleteUser",
+			Handler:    _Identity_DeleteUser_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Identity_Login_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _Identity_IntrospectToken_Handler,
+		},
+		{
+			MethodName: "ValidateToken",
+			Handler:    _Identity_ValidateToken_Handler,
+		},
+		{
+			MethodName: "IsAllowed",
+			Handler:    _Identity_IsAllowed_Handler,
+		},
+		{
+			MethodName: "TokenAllowed",
+			Handler:    _Identity_TokenAllowed_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _Identity_ChangePassword_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -134,16 +773,88 @@ var _Identity_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("identity.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 162 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0xcb, 0x4c, 0x49, 0xcd,
-	0x2b, 0xc9, 0x2c, 0xa9, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x80, 0xf1, 0x95, 0x3c,
-	0xb9, 0xf8, 0x83, 0x52, 0xd3, 0x33, 0x8b, 0x4b, 0x52, 0x8b, 0x82, 0x52, 0x0b, 0x4b, 0x53, 0x8b,
-	0x4b, 0x84, 0xa4, 0xb8, 0x38, 0x4a, 0x8b, 0x53, 0x8b, 0xf2, 0x12, 0x73, 0x53, 0x25, 0x18, 0x15,
-	0x18, 0x35, 0x38, 0x83, 0xe0, 0x7c, 0x90, 0x5c, 0x41, 0x62, 0x71, 0x71, 0x79, 0x7e, 0x51, 0x8a,
-	0x04, 0x13, 0x44, 0x0e, 0xc6, 0x57, 0xb2, 0xe3, 0x12, 0x40, 0x18, 0x55, 0x5c, 0x90, 0x9f, 0x57,
-	0x9c, 0x2a, 0xc4, 0xc7, 0xc5, 0x94, 0x99, 0x02, 0x35, 0x85, 0x29, 0x33, 0x05, 0xc5, 0x6c, 0x26,
-	0x54, 0xb3, 0x8d, 0xfc, 0xb9, 0x38, 0x3c, 0xa1, 0xce, 0x12, 0x72, 0xe6, 0xe2, 0x80, 0x99, 0x25,
-	0x24, 0xa9, 0x07, 0x77, 0x3d, 0x9a, 0x53, 0xa5, 0xa4, 0xb0, 0x49, 0x41, 0xac, 0x56, 0x62, 0x48,
-	0x62, 0x03, 0x7b, 0xd6, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0x0e, 0x21, 0x23, 0xd1, 0xfe, 0x00,
-	0x00, 0x00,
+	// 1324 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x57, 0x4b, 0x6f, 0x1c, 0xc5,
+	0x13, 0xf7, 0xbe, 0x67, 0x6b, 0x1f, 0x76, 0xfa, 0xef, 0xd8, 0x93, 0xcd, 0xdf, 0x89, 0x33, 0x09,
+	0x90, 0x20, 0x61, 0x09, 0x23, 0x50, 0x14, 0x45, 0x80, 0xe5, 0x04, 0x64, 0x29, 0x88, 0x68, 0x12,
+	0x23, 0x4e, 0xac, 0xc6, 0x33, 0x95, 0x65, 0xc8, 0x66, 0x66, 0xe9, 0xee, 0xf5, 0x23, 0x27, 0x8e,
+	0x9c, 0xf9, 0x48, 0x7c, 0x0b, 0x38, 0x22, 0x71, 0xe7, 0x23, 0xa0, 0xae, 0xee, 0x9e, 0x99, 0x9d,
+	0x7d, 0x18, 0x0b, 0x6e, 0x53, 0xbf, 0xaa, 0xee, 0xae, 0xfa, 0x55, 0x55, 0x77, 0x0d, 0xf4, 0xe3,
+	0x08, 0x13, 0x19, 0xcb, 0x8b, 0xbd, 0x09, 0x4f, 0x65, 0xca, 0x1c, 0x2b, 0x7b, 0x3f, 0xd5, 0x60,
+	0xdd, 0xc7, 0x51, 0x2c, 0x24, 0x72, 0x1f, 0x7f, 0x9c, 0xa2, 0x90, 0x6c, 0x00, 0xce, 0x54, 0x20,
+	0x4f, 0x82, 0x37, 0xe8, 0x56, 0x76, 0x2b, 0xf7, 0xdb, 0x7e, 0x26, 0x2b, 0xdd, 0x24, 0x10, 0xe2,
+	0x2c, 0xe5, 0x91, 0x5b, 0xd5, 0x3a, 0x2b, 0xb3, 0x3e, 0x54, 0xe3, 0xc8, 0xad, 0x11, 0x5a, 0x8d,
+	0x23, 0x76, 0x17, 0x7a, 0x1c, 0xa3, 0x98, 0x63, 0x28, 0x87, 0x53, 0x1e, 0x0b, 0xb7, 0xbe, 0x5b,
+	0xbb, 0xdf, 0xf6, 0xbb, 0x16, 0x3c, 0xe6, 0xb1, 0x60, 0xb7, 0xa1, 0x33, 0xe2, 0x41, 0x22, 0x87,
+	0xf2, 0x62, 0x82, 0xc2, 0x6d, 0x90, 0x09, 0x10, 0xf4, 0x52, 0x21, 0xec, 0x1d, 0xe8, 0x73, 0x14,
+	0x93, 0x34, 0x11, 0x68, 0x6c, 0x9a, 0x64, 0xd3, 0xb3, 0xa8, 0x36, 0xdb, 0x84, 0x86, 0x08, 0xd3,
+	0x09, 0xba, 0x2d, 0x3a, 0x5f, 0x0b, 0x0a, 0x4d, 0xcf, 0x12, 0xe4, 0xae, 0xa3, 0x51, 0x12, 0xd8,
+	0x0e, 0xc0, 0x24, 0x1d, 0xc7, 0xe1, 0x85, 0x72, 0xcb, 0x6d, 0x93, 0xaa, 0xad, 0x91, 0x63, 0x1e,
+	0xb3, 0x6d, 0x68, 0xc9, 0x54, 0x90, 0x0e, 0x48, 0xd7, 0x94, 0xa9, 0x50, 0x8a, 0x1d, 0x80, 0x70,
+	0x1c, 0x63, 0x42, 0xe1, 0xb8, 0x1d, 0xbd, 0x4e, 0x23, 0x4a, 0x7d, 0x03, 0x9c, 0x71, 0x3a, 0x4a,
+	0x49, 0xd9, 0x25, 0x65, 0x4b, 0xc9, 0x4a, 0x35, 0x00, 0x27, 0x4c, 0x13, 0x19, 0x84, 0x52, 0xb8,
+	0x3d, 0x72, 0x3f, 0x93, 0xbd, 0x3f, 0xab, 0xb0, 0x91, 0xa7, 0x40, 0xc7, 0x64, 0xb8, 0xac, 0x64,
+	0x5c, 0x16, 0x73, 0x52, 0x2d, 0xe5, 0x64, 0x8e, 0xe7, 0xda, 0xe5, 0x3c, 0xd7, 0xff, 0x01, 0xcf,
+	0x8d, 0x95, 0x3c, 0x37, 0x17, 0xf2, 0xdc, 0x5a, 0xce, 0xb3, 0xb3, 0x82, 0xe7, 0xf6, 0x0a, 0x9e,
+	0x61, 0x15, 0xcf, 0x9d, 0xe5, 0x3c, 0x77, 0x4b, 0x3c, 0xef, 0x43, 0xfd, 0x58, 0x20, 0xbf, 0x0a,
+	0xb5, 0xde, 0x63, 0xe8, 0x7f, 0x89, 0xf2, 0x58, 0xe4, 0xcd, 0x71, 0x95, 0xd5, 0x67, 0xb0, 0xf1,
+	0x2c, 0x16, 0xb4, 0x5c, 0xd8, 0xf5, 0x37, 0xa1, 0x3d, 0x09, 0x46, 0x38, 0x14, 0xf1, 0x5b, 0xdd,
+	0x5d, 0x0d, 0xd5, 0x41, 0x23, 0x7c, 0x11, 0xbf, 0x45, 0x22, 0x4c, 0x29, 0x65, 0xfa, 0x1a, 0x13,
+	0xb3, 0x1d, 0x99, 0xbf, 0x54, 0x00, 0x7b, 0x0f, 0xd6, 0xed, 0xde, 0xc3, 0x09, 0xc7, 0x57, 0xf1,
+	0xb9, 0xe9, 0xb6, 0xbe, 0x85, 0x9f, 0x13, 0xea, 0x05, 0x70, 0xad, 0x70, 0xb0, 0x29, 0xa9, 0x7b,
+	0xd0, 0x50, 0x66, 0xc2, 0xad, 0xec, 0xd6, 0xee, 0x77, 0xf6, 0xfb, 0x7b, 0xd9, 0xa5, 0x40, 0xf1,
+	0x69, 0x25, 0x7b, 0x17, 0xd6, 0x13, 0x3c, 0x97, 0xc3, 0x39, 0x3f, 0x7a, 0x0a, 0x7e, 0x6e, 0x7d,
+	0xf1, 0xee, 0xc2, 0xb5, 0x27, 0x38, 0x46, 0x89, 0x2b, 0xc8, 0xf1, 0x36, 0x81, 0x15, 0x8d, 0xb4,
+	0x23, 0xde, 0x77, 0xd0, 0x7d, 0x96, 0x8e, 0xe2, 0xe4, 0xdf, 0xde, 0x37, 0x5b, 0xd0, 0xa4, 0xea,
+	0xb3, 0x05, 0x6f, 0x24, 0xef, 0xe7, 0x0a, 0xf4, 0xcc, 0x01, 0x26, 0xf4, 0x3b, 0xd0, 0x0d, 0xc2,
+	0x10, 0x85, 0x30, 0x11, 0xe9, 0x53, 0x3a, 0x1a, 0xd3, 0xdc, 0xee, 0x00, 0x90, 0x8e, 0x6a, 0xdf,
+	0x52, 0x4f, 0x88, 0xaa, 0x7b, 0xa5, 0xc6, 0xf3, 0x49, 0xcc, 0x51, 0x0c, 0x03, 0x49, 0xac, 0xd7,
+	0xfc, 0xb6, 0x41, 0x0e, 0x64, 0xc1, 0x95, 0xfa, 0x8c, 0x2b, 0x7b, 0xb0, 0x75, 0x94, 0x48, 0x9e,
+	0x8a, 0x09, 0x86, 0x92, 0x0e, 0xb2, 0x41, 0x6f, 0x42, 0xa3, 0xe8, 0x8b, 0x16, 0xbc, 0x3f, 0xaa,
+	0xb0, 0x3d, 0xb7, 0xc0, 0x04, 0xb1, 0x05, 0xcd, 0x20, 0x94, 0xf1, 0xa9, 0x26, 0xc9, 0xf1, 0x8d,
+	0x94, 0x77, 0x64, 0xb5, 0xd8, 0x91, 0x37, 0xc1, 0x74, 0xcc, 0x30, 0xbb, 0x93, 0x1d, 0x0d, 0x1c,
+	0x45, 0x6c, 0x03, 0x6a, 0x62, 0x7a, 0xe2, 0xd6, 0x09, 0x56, 0x9f, 0x0a, 0xc1, 0xf3, 0x89, 0xdb,
+	0xa0, 0xc0, 0xd4, 0xa7, 0x42, 0xe2, 0x40, 0x52, 0x9b, 0xd7, 0x7c, 0xf5, 0xa9, 0x90, 0xe4, 0xe4,
+	0x15, 0xb5, 0x78, 0xcd, 0x57, 0x9f, 0x33, 0x99, 0x73, 0x08, 0xce, 0x33, 0xb7, 0x01, 0xb5, 0x60,
+	0x1a, 0x99, 0xce, 0x56, 0x9f, 0xb4, 0xa3, 0x10, 0xa6, 0x9f, 0xd5, 0x27, 0x7b, 0xac, 0x4e, 0x95,
+	0x6e, 0x87, 0x0a, 0xf2, 0xfd, 0xbc, 0x20, 0x97, 0x50, 0xb0, 0xf7, 0xf4, 0x5c, 0x3e, 0x4d, 0x24,
+	0xbf, 0x50, 0x1e, 0xca, 0xc1, 0x27, 0xe0, 0x58, 0x40, 0xed, 0xfd, 0x1a, 0x2f, 0x0c, 0x99, 0xea,
+	0x53, 0xd1, 0x72, 0x1a, 0x8c, 0xa7, 0x19, 0x2d, 0x24, 0x3c, 0xaa, 0x3e, 0xac, 0x78, 0x4f, 0x60,
+	0xf3, 0x9b, 0x60, 0x1c, 0x47, 0x81, 0xc4, 0xcb, 0x53, 0x52, 0x48, 0x6d, 0x75, 0x26, 0xb5, 0xbf,
+	0x57, 0xe1, 0x7a, 0x69, 0x1b, 0x93, 0x28, 0x7d, 0xb2, 0x69, 0x04, 0xc7, 0xd7, 0x02, 0x73, 0xa1,
+	0x25, 0xa6, 0x27, 0x3f, 0x60, 0x28, 0x8d, 0x47, 0x56, 0x54, 0x37, 0x2f, 0xdd, 0xc3, 0x18, 0x0d,
+	0x67, 0xea, 0xb9, 0x67, 0xd0, 0x17, 0x04, 0x2a, 0x47, 0x62, 0x21, 0xa6, 0xc8, 0x4d, 0xde, 0x8c,
+	0xa4, 0x92, 0x10, 0x4c, 0xa3, 0x18, 0x93, 0x10, 0x29, 0x7f, 0x6d, 0x3f, 0x93, 0x55, 0x15, 0x90,
+	0x55, 0x34, 0xcc, 0x52, 0xe9, 0x68, 0xe0, 0x40, 0x96, 0x6a, 0xba, 0x55, 0xae, 0xe9, 0xcf, 0xa1,
+	0x81, 0xe7, 0x92, 0x07, 0xae, 0x53, 0x4e, 0xcf, 0xc2, 0xb0, 0x55, 0x72, 0x78, 0xa0, 0xd3, 0xa3,
+	0x17, 0x0e, 0x1e, 0x02, 0xe4, 0xe0, 0x95, 0x52, 0xf4, 0x5b, 0x05, 0x7a, 0x07, 0xd4, 0x9d, 0x85,
+	0x4b, 0x82, 0xa3, 0x48, 0xa7, 0x3c, 0xcc, 0x2e, 0x09, 0x2b, 0xdb, 0xce, 0x48, 0xed, 0x55, 0x65,
+	0xa4, 0x22, 0xe5, 0xb5, 0x59, 0xca, 0x3f, 0x85, 0x96, 0x7a, 0x17, 0x54, 0xf1, 0xd5, 0x29, 0xba,
+	0x7b, 0x79, 0x74, 0x33, 0xe7, 0xee, 0x1d, 0x6a, 0x33, 0x1d, 0x97, 0x5d, 0x34, 0x78, 0x04, 0xdd,
+	0xa2, 0xe2, 0x4a, 0xb1, 0x7d, 0x00, 0xd7, 0x8e, 0xc4, 0xc1, 0x78, 0x9c, 0x9e, 0x61, 0x94, 0xd5,
+	0x8c, 0x0b, 0xad, 0x40, 0x43, 0xa6, 0x6a, 0xac, 0xe8, 0x9d, 0xc2, 0xff, 0x88, 0xe7, 0x6c, 0xc5,
+	0xaa, 0x62, 0xfd, 0x10, 0x5a, 0x5c, 0x1b, 0xd0, 0xb9, 0x9d, 0xfd, 0xed, 0x25, 0x71, 0xf9, 0xd6,
+	0x6e, 0xe9, 0x2d, 0xfa, 0x6b, 0x15, 0x36, 0x67, 0x0f, 0xce, 0x5d, 0xb5, 0xac, 0x56, 0x2e, 0x2b,
+	0xe4, 0xea, 0xea, 0x42, 0xae, 0x2d, 0x2d, 0xe4, 0xfa, 0xaa, 0x42, 0x6e, 0xac, 0x2c, 0xe4, 0x66,
+	0xb9, 0x90, 0x3f, 0xb3, 0x85, 0xdc, 0xa2, 0x54, 0x3f, 0xc8, 0x29, 0x59, 0x14, 0xdf, 0x7f, 0x5a,
+	0xc7, 0xbf, 0x54, 0xe0, 0xfa, 0xe1, 0xf7, 0x41, 0x32, 0xc2, 0xe7, 0xe6, 0xd5, 0x5a, 0x36, 0x47,
+	0xdc, 0x81, 0x6e, 0x3a, 0x8e, 0x86, 0xa5, 0xc7, 0xae, 0x93, 0x8e, 0x23, 0xbb, 0x52, 0x99, 0x24,
+	0x78, 0x96, 0x9b, 0x68, 0xf6, 0x3a, 0x09, 0x9e, 0x65, 0x26, 0x34, 0x0a, 0x9e, 0xa6, 0xaf, 0xcd,
+	0xd3, 0x2d, 0x88, 0x47, 0xc7, 0xef, 0x6a, 0x90, 0xc2, 0x15, 0x9e, 0x0b, 0x5b, 0x65, 0x9f, 0xcc,
+	0xcb, 0x7c, 0x01, 0x9d, 0xa7, 0x9c, 0xa7, 0xfc, 0x09, 0xca, 0x20, 0x1e, 0xab, 0x44, 0x71, 0x0c,
+	0x44, 0x6a, 0x8b, 0xcc, 0x48, 0x34, 0x87, 0x4c, 0x84, 0xe4, 0x18, 0xbc, 0x19, 0x0a, 0x19, 0xc8,
+	0xa9, 0x20, 0x77, 0x1b, 0x7e, 0xdf, 0xc2, 0x2f, 0x08, 0x65, 0x0f, 0x60, 0x23, 0x33, 0x7c, 0x83,
+	0x42, 0x04, 0x23, 0x34, 0x5e, 0x67, 0x1b, 0x7c, 0xa5, 0xe1, 0xfd, 0xbf, 0x1a, 0xe0, 0x1c, 0x99,
+	0xbc, 0xb0, 0x43, 0x70, 0xec, 0x44, 0xcc, 0x6e, 0xe4, 0xe9, 0x2a, 0xfd, 0xa8, 0x0c, 0x06, 0x8b,
+	0x54, 0x26, 0x94, 0x35, 0xf6, 0x31, 0xb4, 0xcc, 0xec, 0xc6, 0xdc, 0xdc, 0x70, 0x76, 0x9c, 0x1b,
+	0x94, 0xa6, 0x20, 0x6f, 0x8d, 0x7d, 0x01, 0xed, 0x6c, 0x76, 0x62, 0x85, 0x13, 0xca, 0x93, 0xdc,
+	0xe0, 0xe6, 0x42, 0x5d, 0x76, 0xfc, 0x11, 0x40, 0x3e, 0xfb, 0xb0, 0x82, 0xf1, 0xdc, 0xd8, 0x34,
+	0xf8, 0xff, 0x62, 0x65, 0xb6, 0xd5, 0x23, 0x68, 0xd0, 0x3c, 0xc3, 0xb6, 0x0a, 0x47, 0x16, 0x26,
+	0xa8, 0xc1, 0xf6, 0x1c, 0x9e, 0xad, 0xfd, 0x16, 0xd6, 0x4b, 0xaf, 0x29, 0xdb, 0x5d, 0xf1, 0xd0,
+	0xea, 0xfd, 0xee, 0x5c, 0xfa, 0x14, 0x7b, 0x6b, 0xcc, 0x87, 0xde, 0xcc, 0x43, 0xc0, 0x6e, 0x2d,
+	0x7d, 0x21, 0xf4, 0xae, 0xb7, 0x2f, 0x79, 0x41, 0xbc, 0x35, 0x76, 0x08, 0xed, 0xec, 0x6e, 0x64,
+	0xcb, 0xee, 0xae, 0x22, 0xf3, 0x73, 0x37, 0xa9, 0xb7, 0xc6, 0xbe, 0x86, 0x6e, 0xb1, 0xb1, 0xd9,
+	0xce, 0xb2, 0x86, 0xd7, 0xbb, 0xdd, 0x5a, 0x7d, 0x1f, 0x78, 0x6b, 0xec, 0x18, 0xfa, 0xb3, 0x0d,
+	0xc3, 0x0a, 0xa1, 0x2c, 0x6c, 0xef, 0xc1, 0xee, 0x72, 0x03, 0xbb, 0xed, 0x49, 0x93, 0x7e, 0xc6,
+	0x3f, 0xfa, 0x7b, 0x00, 0xdf, 0xdc, 0x54, 0x0f, 0x9e, 0x0f, 0x00, 0x00,
 }